@@ -145,6 +145,10 @@ var (
 	// will send to a given follower without hearing a response.
 	defaultRaftMaxInflightMsgs = envutil.EnvOrDefaultInt(
 		"COCKROACH_RAFT_MAX_INFLIGHT_MSGS", 128)
+
+	// defaultRaftEnableCheckQuorum specifies whether Raft CheckQuorum is enabled.
+	defaultRaftEnableCheckQuorum = envutil.EnvOrDefaultBool(
+		"COCKROACH_RAFT_ENABLE_CHECKQUORUM", false)
 )
 
 // Config is embedded by server.Config. A base config is not meant to be used
@@ -364,6 +368,17 @@ type RaftConfig struct {
 	//
 	// -1 to disable.
 	RaftDelaySplitToSuppressSnapshotTicks int
+
+	// RaftEnableCheckQuorum enables Raft CheckQuorum. A leader that hasn't
+	// heard from a quorum of followers within an election timeout steps down,
+	// and a follower that has recently heard from its leader rejects (pre)votes
+	// from other candidates, so a partitioned or restarted replica can't disrupt
+	// an established leader. PreVote is always enabled.
+	//
+	// Ranges don't quiesce while CheckQuorum is enabled, since quiesced
+	// followers stop ticking and would keep rejecting votes after the leader
+	// failed.
+	RaftEnableCheckQuorum bool
 }
 
 // SetDefaults initializes unset fields.
@@ -413,6 +428,9 @@ func (cfg *RaftConfig) SetDefaults() {
 	if cfg.RaftMaxInflightMsgs == 0 {
 		cfg.RaftMaxInflightMsgs = defaultRaftMaxInflightMsgs
 	}
+	if !cfg.RaftEnableCheckQuorum {
+		cfg.RaftEnableCheckQuorum = defaultRaftEnableCheckQuorum
+	}
 	if cfg.RaftDelaySplitToSuppressSnapshotTicks == 0 {
 		// The Raft Ticks interval defaults to 200ms, and an election is 15
 		// ticks. Add a generous amount of ticks to make sure even a backed up
@@ -373,8 +373,10 @@ func (c *Connector) RegisterSystemConfigChannel() (_ <-chan struct{}, unregister
 }
 
 // RangeLookup implements the kvcoord.RangeDescriptorDB interface.
+//
+// The requested prefetchNum is ignored; see the comment on PrefetchNum below.
 func (c *Connector) RangeLookup(
-	ctx context.Context, key roachpb.RKey, useReverseScan bool,
+	ctx context.Context, key roachpb.RKey, _ int64, useReverseScan bool,
 ) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error) {
 	// Proxy range lookup requests through the Internal service.
 	ctx = c.AnnotateCtx(ctx)
@@ -336,7 +336,8 @@ func TestConnectorRangeLookup(t *testing.T) {
 	rangeLookupRespC <- &roachpb.RangeLookupResponse{
 		Descriptors: descs, PrefetchedDescriptors: preDescs,
 	}
-	resDescs, resPreDescs, err := c.RangeLookup(ctx, roachpb.RKey("a"), false /* useReverseScan */)
+	resDescs, resPreDescs, err := c.RangeLookup(
+		ctx, roachpb.RKey("a"), 0 /* prefetchNum */, false /* useReverseScan */)
 	require.Equal(t, descs, resDescs)
 	require.Equal(t, preDescs, resPreDescs)
 	require.NoError(t, err)
@@ -345,7 +346,8 @@ func TestConnectorRangeLookup(t *testing.T) {
 	rangeLookupRespC <- &roachpb.RangeLookupResponse{
 		Error: roachpb.NewErrorf("hit error"),
 	}
-	resDescs, resPreDescs, err = c.RangeLookup(ctx, roachpb.RKey("a"), false /* useReverseScan */)
+	resDescs, resPreDescs, err = c.RangeLookup(
+		ctx, roachpb.RKey("a"), 0 /* prefetchNum */, false /* useReverseScan */)
 	require.Nil(t, resDescs)
 	require.Nil(t, resPreDescs)
 	require.Regexp(t, "hit error", err)
@@ -362,7 +364,8 @@ func TestConnectorRangeLookup(t *testing.T) {
 		blockingC <- struct{}{}
 		cancel()
 	}()
-	resDescs, resPreDescs, err = c.RangeLookup(canceledCtx, roachpb.RKey("a"), false /* useReverseScan */)
+	resDescs, resPreDescs, err = c.RangeLookup(
+		canceledCtx, roachpb.RKey("a"), 0 /* prefetchNum */, false /* useReverseScan */)
 	require.Nil(t, resDescs)
 	require.Nil(t, resPreDescs)
 	require.Regexp(t, context.Canceled.Error(), err)
@@ -543,7 +546,7 @@ func TestConnectorRetriesError(t *testing.T) {
 			// iterations as server choice is random and we need to hit failure only once
 			// to check if it was retried.
 			for i := 0; i < 100; i++ {
-				_, _, err := c.RangeLookup(ctx, roachpb.RKey("a"), false)
+				_, _, err := c.RangeLookup(ctx, roachpb.RKey("a"), 0 /* prefetchNum */, false)
 				if atomic.LoadInt32(&errorsReported) == 0 {
 					continue
 				}
@@ -334,6 +334,12 @@ const (
 	// AlterSystemStatementStatisticsAddIndexRecommendations adds an
 	// index_recommendations column to the system.statement_statistics table.
 	AlterSystemStatementStatisticsAddIndexRecommendations
+	// IncrementBounds enables bounded increments, i.e. IncrementRequests
+	// carrying IncrementBounds. Nodes running older versions ignore the bounds.
+	IncrementBounds
+	// FilteredScans enables filtered scans, i.e. Scan and ReverseScan requests
+	// carrying a ScanFilter. Nodes running older versions ignore the filter.
+	FilteredScans
 
 	// *************************************************
 	// Step (1): Add new versions here.
@@ -579,6 +585,14 @@ var versionsSingleton = keyedVersions{
 		Key:     AlterSystemStatementStatisticsAddIndexRecommendations,
 		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 32},
 	},
+	{
+		Key:     IncrementBounds,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 34},
+	},
+	{
+		Key:     FilteredScans,
+		Version: roachpb.Version{Major: 22, Minor: 1, Internal: 36},
+	},
 
 	// *************************************************
 	// Step (2): Add new versions here.
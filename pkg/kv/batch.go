@@ -533,16 +533,39 @@ func (b *Batch) InitPut(key, value interface{}, failOnTombstones bool) {
 //
 // key can be either a byte slice or a string.
 func (b *Batch) Inc(key interface{}, value int64) {
+	b.incInternal(key, value, nil /* bounds */)
+}
+
+// IncBounded is like Inc, but constrains the incremented value to the
+// inclusive range [bounds.Min, bounds.Max]. If the incremented value would
+// fall outside of it, the nearest bound is stored if bounds.Clamp is set;
+// otherwise Result.Err is set to a ConditionFailedError carrying the existing
+// value and nothing is written.
+//
+// key can be either a byte slice or a string.
+func (b *Batch) IncBounded(key interface{}, value int64, bounds roachpb.IncrementBounds) {
+	b.incInternal(key, value, &bounds)
+}
+
+func (b *Batch) incInternal(key interface{}, value int64, bounds *roachpb.IncrementBounds) {
 	k, err := marshalKey(key)
 	if err != nil {
 		b.initResult(0, 1, notRaw, err)
 		return
 	}
-	b.appendReqs(roachpb.NewIncrement(k, value))
+	req := roachpb.NewIncrement(k, value).(*roachpb.IncrementRequest)
+	req.Bounds = bounds
+	b.appendReqs(req)
 	b.initResult(1, 1, notRaw, nil)
 }
 
 func (b *Batch) scan(s, e interface{}, isReverse, forUpdate bool) {
+	b.scanInternal(s, e, isReverse, forUpdate, nil /* filter */)
+}
+
+func (b *Batch) scanInternal(
+	s, e interface{}, isReverse, forUpdate bool, filter *roachpb.ScanFilter,
+) {
 	begin, err := marshalKey(s)
 	if err != nil {
 		b.initResult(0, 0, notRaw, err)
@@ -554,9 +577,13 @@ func (b *Batch) scan(s, e interface{}, isReverse, forUpdate bool) {
 		return
 	}
 	if !isReverse {
-		b.appendReqs(roachpb.NewScan(begin, end, forUpdate))
+		req := roachpb.NewScan(begin, end, forUpdate).(*roachpb.ScanRequest)
+		req.Filter = filter
+		b.appendReqs(req)
 	} else {
-		b.appendReqs(roachpb.NewReverseScan(begin, end, forUpdate))
+		req := roachpb.NewReverseScan(begin, end, forUpdate).(*roachpb.ReverseScanRequest)
+		req.Filter = filter
+		b.appendReqs(req)
 	}
 	b.initResult(1, 0, notRaw, nil)
 }
@@ -572,6 +599,16 @@ func (b *Batch) Scan(s, e interface{}) {
 	b.scan(s, e, false /* isReverse */, false /* forUpdate */)
 }
 
+// ScanFiltered is like Scan, but only returns the key/values matching filter.
+// The filter is evaluated on the server. Key/values that don't match it are
+// still charged against the batch's MaxSpanRequestKeys and TargetBytes limits,
+// so a limited batch may return a resume span without any key/values.
+//
+// key can be either a byte slice or a string.
+func (b *Batch) ScanFiltered(s, e interface{}, filter roachpb.ScanFilter) {
+	b.scanInternal(s, e, false /* isReverse */, false /* forUpdate */, &filter)
+}
+
 // ScanForUpdate retrieves the key/values between begin (inclusive) and end
 // (exclusive) in ascending order. Unreplicated, exclusive locks are acquired on
 // each of the returned keys.
@@ -595,6 +632,14 @@ func (b *Batch) ReverseScan(s, e interface{}) {
 	b.scan(s, e, true /* isReverse */, false /* forUpdate */)
 }
 
+// ReverseScanFiltered is like ReverseScan, but only returns the rows matching
+// filter. See ScanFiltered.
+//
+// key can be either a byte slice or a string.
+func (b *Batch) ReverseScanFiltered(s, e interface{}, filter roachpb.ScanFilter) {
+	b.scanInternal(s, e, true /* isReverse */, false /* forUpdate */, &filter)
+}
+
 // ReverseScanForUpdate retrieves the rows between begin (inclusive) and end
 // (exclusive) in descending order. Unreplicated, exclusive locks are acquired
 // on each of the returned keys.
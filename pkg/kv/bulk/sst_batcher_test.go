@@ -291,7 +291,7 @@ func runTestImport(t *testing.T, batchSizeValue int64) {
 			require.NoError(t, err)
 
 			tok, err := s.DistSenderI().(*kvcoord.DistSender).RangeDescriptorCache().LookupWithEvictionToken(
-				ctx, addr, rangecache.EvictionToken{}, false)
+				ctx, addr, rangecache.EvictionToken{}, false, rangecache.DefaultLookupOptions())
 			require.NoError(t, err)
 
 			r := roachpb.RangeInfo{
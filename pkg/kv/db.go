@@ -183,7 +183,35 @@ type DBContext struct {
 	NodeID *base.SQLIDContainer
 	// Stopper is used for async tasks.
 	Stopper *stop.Stopper
-}
+	// TxnRetryOptions, if set, configures the (jittered, exponential) backoff
+	// applied between the attempts of transactions run through DB.Txn and its
+	// variants. If MaxRetries is set, the transaction gives up and returns the
+	// last retryable error once that many retries have been made. By default,
+	// retryable errors are retried immediately and indefinitely.
+	TxnRetryOptions *retry.Options
+	// OnTxnRetry, if set, is called before each retry of a transaction run
+	// through DB.Txn and its variants, with the retryable error and the number
+	// of attempts made so far. If it returns an error, the transaction is not
+	// retried; it is rolled back and that error is returned to the caller.
+	OnTxnRetry func(ctx context.Context, attempt int, err error) error
+	// Interceptor, if set, is invoked for every batch run through the DB's
+	// client API, including batches run by its transactions. Batches sent
+	// directly to the NonTransactionalSender bypass it. See RequestInterceptor.
+	Interceptor RequestInterceptor
+}
+
+// RequestInterceptor observes (and possibly alters) a batch on its way out of a
+// DB. It is handed the next Sender in the chain and is responsible for calling
+// it; this lets it log or record metrics about requests and responses, inject
+// errors in tests, or retry batches that failed with an ambiguous result when
+// the application knows them to be idempotent.
+//
+// Transactional batches are intercepted before they reach the transaction's
+// TxnSender, so they don't carry a Transaction proto yet and errors returned
+// for them are subject to the usual transaction retry handling.
+type RequestInterceptor func(
+	ctx context.Context, ba roachpb.BatchRequest, next Sender,
+) (*roachpb.BatchResponse, *roachpb.Error)
 
 // DefaultDBContext returns (a copy of) the default options for
 // NewDBWithContext.
@@ -458,6 +486,18 @@ func (db *DB) Inc(ctx context.Context, key interface{}, value int64) (KeyValue,
 	return getOneRow(db.Run(ctx, b), b)
 }
 
+// IncBounded is like Inc, but constrains the incremented value to the given
+// bounds. See Batch.IncBounded.
+//
+// key can be either a byte slice or a string.
+func (db *DB) IncBounded(
+	ctx context.Context, key interface{}, value int64, bounds roachpb.IncrementBounds,
+) (KeyValue, error) {
+	b := &Batch{}
+	b.IncBounded(key, value, bounds)
+	return getOneRow(db.Run(ctx, b), b)
+}
+
 func (db *DB) scan(
 	ctx context.Context,
 	begin, end interface{},
@@ -993,7 +1033,13 @@ func (db *DB) sendUsingSender(
 		ba.UserPriority = db.ctx.UserPriority
 	}
 
-	br, pErr := sender.Send(ctx, ba)
+	var br *roachpb.BatchResponse
+	var pErr *roachpb.Error
+	if interceptor := db.ctx.Interceptor; interceptor != nil {
+		br, pErr = interceptor(ctx, ba, sender)
+	} else {
+		br, pErr = sender.Send(ctx, ba)
+	}
 	if pErr != nil {
 		if log.V(1) {
 			log.Infof(ctx, "failed batch: %s", pErr)
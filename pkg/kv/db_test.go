@@ -287,6 +287,41 @@ func TestDB_Inc(t *testing.T) {
 	checkIntResult(t, 100, result.ValueInt())
 }
 
+func TestDB_IncBounded(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	s, db := setup(t)
+	defer s.Stopper().Stop(context.Background())
+	ctx := context.Background()
+
+	bounds := roachpb.IncrementBounds{Min: 0, Max: 10}
+	if _, err := db.IncBounded(ctx, "aa", 8, bounds); err != nil {
+		t.Fatal(err)
+	}
+
+	// Going out of bounds fails, and the error carries the existing value.
+	_, err := db.IncBounded(ctx, "aa", 5, bounds)
+	var cErr *roachpb.ConditionFailedError
+	require.True(t, errors.As(err, &cErr), "unexpected error: %v", err)
+	require.NotNil(t, cErr.ActualValue)
+	actual, err := cErr.ActualValue.GetInt()
+	require.NoError(t, err)
+	checkIntResult(t, 8, actual)
+	result, err := db.Get(ctx, "aa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIntResult(t, 8, result.ValueInt())
+
+	// Clamping stores the nearest bound instead.
+	bounds.Clamp = true
+	result, err = db.IncBounded(ctx, "aa", 5, bounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIntResult(t, 10, result.ValueInt())
+}
+
 func TestBatch(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -11,6 +11,7 @@
 package kvcoord
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"runtime"
@@ -21,6 +22,7 @@ import (
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -44,6 +46,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/logtags"
 	"github.com/cockroachdb/redact"
 )
 
@@ -60,6 +63,16 @@ var (
 		Measurement: "Partial Batches",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaDistSenderPartialBatchRetryCount = metric.Metadata{
+		Name: "distsender.batches.partial.retried",
+		Help: `Number of times a partial batch was resent to its range
+
+This counts retries performed after failing to reach any replica of the range or
+failing to look up the range's descriptor. Retries against other replicas of the
+same range are counted by distsender.rpc.sent.nextreplicaerror.`,
+		Measurement: "Partial Batches",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaDistSenderAsyncSentCount = metric.Metadata{
 		Name:        "distsender.batches.async.sent",
 		Help:        "Number of partial batches sent asynchronously",
@@ -90,6 +103,18 @@ var (
 		Measurement: "RPCs",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaDistSenderHedgedReadCount = metric.Metadata{
+		Name:        "distsender.rpc.hedged",
+		Help:        "Number of speculative duplicate RPCs sent for slow read-only batches",
+		Measurement: "RPCs",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaDistSenderHedgedReadWins = metric.Metadata{
+		Name:        "distsender.rpc.hedged.wins",
+		Help:        "Number of speculative duplicate RPCs that returned before the original RPC",
+		Measurement: "RPCs",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaDistSenderNotLeaseHolderErrCount = metric.Metadata{
 		Name:        "distsender.errors.notleaseholder",
 		Help:        "Number of NotLeaseHolderErrors encountered from replica-addressed RPCs",
@@ -183,8 +208,6 @@ var CanSendToFollower = func(
 const (
 	// The default limit for asynchronous senders.
 	defaultSenderConcurrency = 1024
-	// The maximum number of range descriptors to prefetch during range lookups.
-	rangeLookupPrefetchCount = 8
 	// The maximum number of times a replica is retried when it repeatedly returns
 	// stale lease info.
 	sameReplicaRetryLimit = 10
@@ -197,6 +220,28 @@ var rangeDescriptorCacheSize = settings.RegisterIntSetting(
 	1e6,
 )
 
+var rangeDescriptorCacheMaxBytes = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"kv.range_descriptor_cache.max_bytes",
+	"maximum estimated memory footprint of the entries in the range descriptor cache; "+
+		"0 disables the limit, leaving only the limit on the number of entries",
+	0,
+	settings.NonNegativeInt,
+)
+
+// hedgedReadDelay controls how long DistSender waits for a response to a
+// read-only batch that can be served by any replica before sending a duplicate
+// of it to the next replica in line.
+var hedgedReadDelay = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"kv.dist_sender.hedged_reads.delay",
+	"if non-zero, read-only batches that can be served by any replica and that have not "+
+		"received a response after this delay are also sent to the next replica; the first "+
+		"successful response is used and the other RPC is canceled",
+	0,
+	settings.NonNegativeDuration,
+)
+
 // senderConcurrencyLimit controls the maximum number of asynchronous send
 // requests.
 var senderConcurrencyLimit = settings.RegisterIntSetting(
@@ -218,11 +263,14 @@ func max(a, b int64) int64 {
 type DistSenderMetrics struct {
 	BatchCount              *metric.Counter
 	PartialBatchCount       *metric.Counter
+	PartialBatchRetryCount  *metric.Counter
 	AsyncSentCount          *metric.Counter
 	AsyncThrottledCount     *metric.Counter
 	SentCount               *metric.Counter
 	LocalSentCount          *metric.Counter
 	NextReplicaErrCount     *metric.Counter
+	HedgedReadCount         *metric.Counter
+	HedgedReadWins          *metric.Counter
 	NotLeaseHolderErrCount  *metric.Counter
 	InLeaseTransferBackoffs *metric.Counter
 	RangeLookups            *metric.Counter
@@ -238,11 +286,14 @@ func makeDistSenderMetrics() DistSenderMetrics {
 	m := DistSenderMetrics{
 		BatchCount:              metric.NewCounter(metaDistSenderBatchCount),
 		PartialBatchCount:       metric.NewCounter(metaDistSenderPartialBatchCount),
+		PartialBatchRetryCount:  metric.NewCounter(metaDistSenderPartialBatchRetryCount),
 		AsyncSentCount:          metric.NewCounter(metaDistSenderAsyncSentCount),
 		AsyncThrottledCount:     metric.NewCounter(metaDistSenderAsyncThrottledCount),
 		SentCount:               metric.NewCounter(metaTransportSentCount),
 		LocalSentCount:          metric.NewCounter(metaTransportLocalSentCount),
 		NextReplicaErrCount:     metric.NewCounter(metaTransportSenderNextReplicaErrCount),
+		HedgedReadCount:         metric.NewCounter(metaDistSenderHedgedReadCount),
+		HedgedReadWins:          metric.NewCounter(metaDistSenderHedgedReadWins),
 		NotLeaseHolderErrCount:  metric.NewCounter(metaDistSenderNotLeaseHolderErrCount),
 		InLeaseTransferBackoffs: metric.NewCounter(metaDistSenderInLeaseTransferBackoffsCount),
 		RangeLookups:            metric.NewCounter(metaDistSenderRangeLookups),
@@ -281,6 +332,41 @@ type FirstRangeProvider interface {
 	OnFirstRangeChanged(func(*roachpb.RangeDescriptor))
 }
 
+// RequestRateLimiter throttles the batches sent through a DistSender. Each
+// batch is attributed to a tag identifying its source (see RequestTag), so
+// that limits can be set per job, per internal subsystem or per user, and a
+// runaway source of internal work can be throttled without starving user
+// traffic.
+type RequestRateLimiter interface {
+	// Wait blocks until ba, sent on behalf of tag, may be sent. If an error is
+	// returned, the batch is not sent and the error is returned to the caller.
+	Wait(ctx context.Context, tag string, ba *roachpb.BatchRequest) error
+}
+
+// requestTagKeys are the log tags identifying the source of a batch, in order
+// of precedence. Jobs tag their contexts with the job ID, the internal
+// executor with the name of the operation, and SQL connections with the
+// session's user.
+var requestTagKeys = []string{"job", "intExec", "user"}
+
+// RequestTag returns the tag under which a batch sent with the given context
+// and admission header is rate limited. It is the first of the log tags in
+// requestTagKeys found in ctx, formatted as key=value (e.g. "user=alice"). If
+// there is none, the batch is attributed to its admission source (e.g.
+// "source=ROOT_KV").
+func RequestTag(ctx context.Context, ah roachpb.AdmissionHeader) string {
+	if tags := logtags.FromContext(ctx); tags != nil {
+		for _, key := range requestTagKeys {
+			for _, t := range tags.Get() {
+				if t.Key() == key {
+					return key + "=" + t.ValueStr()
+				}
+			}
+		}
+	}
+	return "source=" + ah.Source.String()
+}
+
 // A DistSender provides methods to access Cockroach's monolithic,
 // distributed key value store. Each method invocation triggers a
 // lookup or lookups to find replica metadata for implicated key
@@ -313,6 +399,7 @@ type DistSender struct {
 	// nodeDialer allows RPC calls from the SQL layer to the KV layer.
 	nodeDialer      *nodedialer.Dialer
 	rpcRetryOptions retry.Options
+	rpcRetryBudget  time.Duration
 	asyncSenderSem  *quotapool.IntPool
 	// clusterID is the logical cluster ID used to verify access to enterprise features.
 	// It is copied out of the rpcContext at construction time and used in
@@ -324,6 +411,9 @@ type DistSender struct {
 	// can potentially throttle requests.
 	kvInterceptor multitenant.TenantSideKVInterceptor
 
+	// rateLimiter, if set, is consulted before each batch is sent.
+	rateLimiter RequestRateLimiter
+
 	// disableFirstRangeUpdates disables updates of the first range via
 	// gossip. Used by tests which want finer control of the contents of the
 	// range cache.
@@ -336,6 +426,10 @@ type DistSender struct {
 	// LatencyFunc is used to estimate the latency to other nodes.
 	latencyFunc LatencyFunc
 
+	// replicaSlicePolicy, if set, orders replicas instead of
+	// ReplicaSlice.OptimizeReplicaOrder.
+	replicaSlicePolicy ReplicaSlicePolicy
+
 	// If set, the DistSender will try the replicas in the order they appear in
 	// the descriptor, instead of trying to reorder them by latency. The knob
 	// only applies to requests sent with the LEASEHOLDER routing policy.
@@ -365,7 +459,13 @@ type DistSenderConfig struct {
 	// Usually it is filled in from the Gossip network on demand.
 	nodeDescriptor  *roachpb.NodeDescriptor
 	RPCRetryOptions *retry.Options
-	RPCContext      *rpc.Context
+	// RPCRetryBudget, if non-zero, bounds the time spent retrying a single
+	// partial batch against a range, for instance while all of its replicas
+	// are unreachable. Once the budget is exhausted, the error from the last
+	// attempt is returned. If zero, retries are bounded only by
+	// RPCRetryOptions.MaxRetries and by the caller's context.
+	RPCRetryBudget time.Duration
+	RPCContext     *rpc.Context
 	// NodeDialer is the dialer from the SQL layer to the KV layer.
 	NodeDialer *nodedialer.Dialer
 
@@ -386,11 +486,34 @@ type DistSenderConfig struct {
 	FirstRangeProvider FirstRangeProvider
 	RangeDescriptorDB  rangecache.RangeDescriptorDB
 
+	// RangeDescriptorCacheMaxAge, if set, is the age after which entries in the
+	// range descriptor cache are re-fetched by lookups, even if they weren't
+	// found to be stale. See rangecache.RangeCache.SetMaxAge.
+	RangeDescriptorCacheMaxAge time.Duration
+
+	// RangeDescriptorCache, if set, is used by the DistSender instead of a range
+	// descriptor cache of its own. This allows multiple DistSenders in the same
+	// process to share a cache (typically the one of the first DistSender,
+	// obtained through DistSender.RangeDescriptorCache), so that range lookups
+	// are amortized across all of them. The shared cache performs range lookups
+	// through the RangeDescriptorDB it was created with, and is configured by its
+	// creator; RangeDescriptorCacheMaxAge is ignored. When set, neither
+	// FirstRangeProvider nor RangeDescriptorDB are required.
+	RangeDescriptorCache *rangecache.RangeCache
+
 	// KVInterceptor is set for tenants; when set, information about all
 	// BatchRequests and BatchResponses are passed through this interceptor, which
 	// can potentially throttle requests.
 	KVInterceptor multitenant.TenantSideKVInterceptor
 
+	// ReplicaSlicePolicy, if set, decides the order in which the replicas of a
+	// range are tried. By default, replicas are ordered by latency and locality.
+	ReplicaSlicePolicy ReplicaSlicePolicy
+
+	// RateLimiter, if set, is consulted before each batch is sent and can
+	// throttle or reject it.
+	RateLimiter RequestRateLimiter
+
 	TestingKnobs ClientTestingKnobs
 }
 
@@ -400,11 +523,13 @@ type DistSenderConfig struct {
 // defaults will be used.
 func NewDistSender(cfg DistSenderConfig) *DistSender {
 	ds := &DistSender{
-		st:            cfg.Settings,
-		clock:         cfg.Clock,
-		nodeDescs:     cfg.NodeDescs,
-		metrics:       makeDistSenderMetrics(),
-		kvInterceptor: cfg.KVInterceptor,
+		st:                 cfg.Settings,
+		clock:              cfg.Clock,
+		nodeDescs:          cfg.NodeDescs,
+		metrics:            makeDistSenderMetrics(),
+		kvInterceptor:      cfg.KVInterceptor,
+		rateLimiter:        cfg.RateLimiter,
+		replicaSlicePolicy: cfg.ReplicaSlicePolicy,
 	}
 	if ds.st == nil {
 		ds.st = cluster.MakeTestingClusterSettings()
@@ -426,14 +551,23 @@ func NewDistSender(cfg DistSenderConfig) *DistSender {
 	if cfg.RangeDescriptorDB != nil {
 		rdb = cfg.RangeDescriptorDB
 	}
-	if rdb == nil {
-		panic("DistSenderConfig must contain either FirstRangeProvider or RangeDescriptorDB")
-	}
-	getRangeDescCacheSize := func() int64 {
-		return rangeDescriptorCacheSize.Get(&ds.st.SV)
+	if cfg.RangeDescriptorCache != nil {
+		ds.rangeCache = cfg.RangeDescriptorCache
+	} else {
+		if rdb == nil {
+			panic("DistSenderConfig must contain either FirstRangeProvider, RangeDescriptorDB " +
+				"or RangeDescriptorCache")
+		}
+		getRangeDescCacheSize := func() int64 {
+			return rangeDescriptorCacheSize.Get(&ds.st.SV)
+		}
+		ds.rangeCache = rangecache.NewRangeCache(ds.st, rdb, getRangeDescCacheSize,
+			cfg.RPCContext.Stopper, cfg.AmbientCtx.Tracer)
+		ds.rangeCache.SetMaxAge(cfg.RangeDescriptorCacheMaxAge)
+		ds.rangeCache.SetMaxBytes(func() int64 {
+			return rangeDescriptorCacheMaxBytes.Get(&ds.st.SV)
+		})
 	}
-	ds.rangeCache = rangecache.NewRangeCache(ds.st, rdb, getRangeDescCacheSize,
-		cfg.RPCContext.Stopper, cfg.AmbientCtx.Tracer)
 	if tf := cfg.TestingKnobs.TransportFactory; tf != nil {
 		ds.transportFactory = tf
 	} else {
@@ -445,6 +579,7 @@ func NewDistSender(cfg DistSenderConfig) *DistSender {
 	if cfg.RPCRetryOptions != nil {
 		ds.rpcRetryOptions = *cfg.RPCRetryOptions
 	}
+	ds.rpcRetryBudget = cfg.RPCRetryBudget
 	if cfg.RPCContext == nil {
 		panic("no RPCContext set in DistSenderConfig")
 	}
@@ -468,7 +603,7 @@ func NewDistSender(cfg DistSenderConfig) *DistSender {
 				return
 			}
 			log.VEventf(ctx, 1, "gossiped first range descriptor: %+v", desc.Replicas())
-			ds.rangeCache.EvictByKey(ctx, roachpb.RKeyMin)
+			ds.rangeCache.UpdateDescriptor(ctx, desc)
 		})
 	}
 
@@ -511,7 +646,7 @@ func (ds *DistSender) RangeDescriptorCache() *rangecache.RangeCache {
 // into DistSender, which will in turn use the RangeDescriptorCache again to
 // lookup the RangeDescriptor necessary to perform the scan.
 func (ds *DistSender) RangeLookup(
-	ctx context.Context, key roachpb.RKey, useReverseScan bool,
+	ctx context.Context, key roachpb.RKey, prefetchNum int64, useReverseScan bool,
 ) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error) {
 	ds.metrics.RangeLookups.Inc(1)
 	// We perform the range lookup scan with a READ_UNCOMMITTED consistency
@@ -527,7 +662,7 @@ func (ds *DistSender) RangeLookup(
 	// RangeDescriptor is not on the first range we send the lookup too, we'll
 	// still find it when we scan to the next range. This addresses the issue
 	// described in #18032 and #16266, allowing us to support meta2 splits.
-	return kv.RangeLookup(ctx, ds, key.AsRawKey(), rc, rangeLookupPrefetchCount, useReverseScan)
+	return kv.RangeLookup(ctx, ds, key.AsRawKey(), rc, prefetchNum, useReverseScan)
 }
 
 // FirstRange implements the RangeDescriptorDB interface.
@@ -614,6 +749,8 @@ func (ds *DistSender) CountRanges(ctx context.Context, rs roachpb.RSpan) (int64,
 // the right range). This is useful for ReverseScans, which call this method
 // with their exclusive EndKey.
 //
+// opts configures the range lookup performed on a cache miss.
+//
 // The returned EvictionToken reflects the close integration between the
 // DistSender and the RangeDescriptorCache; the DistSender concerns itself not
 // only with consuming cached information (the descriptor and lease info come
@@ -623,9 +760,10 @@ func (ds *DistSender) getRoutingInfo(
 	descKey roachpb.RKey,
 	evictToken rangecache.EvictionToken,
 	useReverseScan bool,
+	opts rangecache.LookupOptions,
 ) (rangecache.EvictionToken, error) {
 	returnToken, err := ds.rangeCache.LookupWithEvictionToken(
-		ctx, descKey, evictToken, useReverseScan,
+		ctx, descKey, evictToken, useReverseScan, opts,
 	)
 	if err != nil {
 		return rangecache.EvictionToken{}, err
@@ -714,6 +852,28 @@ func (ds *DistSender) initAndVerifyBatch(
 		return roachpb.NewErrorf("unknown wait policy %s", ba.WaitPolicy)
 	}
 
+	// Reject requests using features that nodes running older versions would
+	// silently ignore.
+	for _, req := range ba.Requests {
+		switch t := req.GetInner().(type) {
+		case *roachpb.IncrementRequest:
+			if t.Bounds != nil && !ds.st.Version.IsActive(ctx, clusterversion.IncrementBounds) {
+				return roachpb.NewErrorf("bounded increments require cluster version %s",
+					clusterversion.ByKey(clusterversion.IncrementBounds))
+			}
+		case *roachpb.ScanRequest:
+			if t.Filter != nil && !ds.st.Version.IsActive(ctx, clusterversion.FilteredScans) {
+				return roachpb.NewErrorf("filtered scans require cluster version %s",
+					clusterversion.ByKey(clusterversion.FilteredScans))
+			}
+		case *roachpb.ReverseScanRequest:
+			if t.Filter != nil && !ds.st.Version.IsActive(ctx, clusterversion.FilteredScans) {
+				return roachpb.NewErrorf("filtered scans require cluster version %s",
+					clusterversion.ByKey(clusterversion.FilteredScans))
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -790,6 +950,12 @@ func (ds *DistSender) Send(
 	ctx, sp := tracing.EnsureChildSpan(ctx, ds.AmbientContext.Tracer, "dist sender send")
 	defer sp.Finish()
 
+	if ds.rateLimiter != nil {
+		if err := ds.rateLimiter.Wait(ctx, RequestTag(ctx, ba.AdmissionHeader), &ba); err != nil {
+			return nil, roachpb.NewError(err)
+		}
+	}
+
 	splitET := false
 	var require1PC bool
 	lastReq := ba.Requests[len(ba.Requests)-1].GetInner()
@@ -1208,6 +1374,9 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 		seekKey = rs.EndKey
 	}
 	ri := MakeRangeIterator(ds)
+	// A batch addressing a single key won't visit any subsequent ranges, so
+	// there's little point in prefetching their descriptors.
+	ri.pointLookup = isSingleKeySpan(rs)
 	ri.Seek(ctx, seekKey, scanDir)
 	if !ri.Valid() {
 		return nil, roachpb.NewError(ri.Error())
@@ -1474,6 +1643,14 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 	return
 }
 
+// isSingleKeySpan returns whether rs addresses a single key, i.e. whether it's
+// of the form [k, k.Next()).
+func isSingleKeySpan(rs roachpb.RSpan) bool {
+	return len(rs.EndKey) == len(rs.Key)+1 &&
+		rs.EndKey[len(rs.Key)] == 0 &&
+		bytes.HasPrefix(rs.EndKey, rs.Key)
+}
+
 // sendPartialBatchAsync sends the partial batch asynchronously if
 // there aren't currently more than the allowed number of concurrent
 // async requests outstanding. Returns whether the partial batch was
@@ -1569,11 +1746,29 @@ func (ds *DistSender) sendPartialBatch(
 	// this loop uses a new descriptor. Attempts to send to multiple replicas in
 	// this descriptor are done at a lower level.
 	tBegin, attempts := timeutil.Now(), int64(0) // for slow log message
+	// tRetryBegin is used to enforce ds.rpcRetryBudget. Unlike tBegin, it is
+	// never reset.
+	tRetryBegin := tBegin
 	// prevTok maintains the EvictionToken used on the previous iteration.
 	var prevTok rangecache.EvictionToken
+	// lastSendErr is set if the previous iteration failed to reach any replica
+	// of the range. It is used to annotate the error returned once we stop
+	// retrying.
+	var lastSendErr error
 	for r := retry.StartWithCtx(ctx, ds.rpcRetryOptions); r.Next(); {
+		if attempts > 0 && ds.rpcRetryBudget > 0 && timeutil.Since(tRetryBegin) > ds.rpcRetryBudget {
+			// pErr holds the error from the previous attempt.
+			log.VEventf(ctx, 1, "giving up after %d attempts; retry budget of %s exhausted",
+				attempts, ds.rpcRetryBudget)
+			break
+		}
+		if attempts > 0 {
+			ds.metrics.PartialBatchRetryCount.Inc(1)
+			log.VEventf(ctx, 2, "resending partial batch (attempt %d) after: %s", attempts+1, pErr)
+		}
 		attempts++
 		pErr = nil
+		lastSendErr = nil
 		// If we've invalidated the descriptor on a send failure, re-lookup.
 		if !routingTok.Valid() {
 			var descKey roachpb.RKey
@@ -1582,7 +1777,11 @@ func (ds *DistSender) sendPartialBatch(
 			} else {
 				descKey = rs.Key
 			}
-			routingTok, err = ds.getRoutingInfo(ctx, descKey, prevTok, isReverse)
+			opts := rangecache.DefaultLookupOptions()
+			if isSingleKeySpan(rs) {
+				opts.PrefetchCount = pointLookupPrefetch
+			}
+			routingTok, err = ds.getRoutingInfo(ctx, descKey, prevTok, isReverse, opts)
 			if err != nil {
 				log.VErrEventf(ctx, 1, "range descriptor re-lookup failed: %s", err)
 				// We set pErr if we encountered an error getting the descriptor in
@@ -1657,6 +1856,7 @@ func (ds *DistSender) sendPartialBatch(
 				// reloading (r4,r5,r6) from the cache on the next iteration.
 				log.VEventf(ctx, 1, "evicting range desc %s after %s", routingTok, err)
 				routingTok.Evict(ctx)
+				lastSendErr = err
 				continue
 			}
 			break
@@ -1728,6 +1928,16 @@ func (ds *DistSender) sendPartialBatch(
 		}
 	}
 
+	// If we gave up after failing to reach any replica of the range, tell the
+	// caller what was tried. A bare sendError only carries the error from the
+	// last replica.
+	if lastSendErr != nil {
+		pErr = roachpb.NewError(errors.Wrapf(lastSendErr,
+			"failed to send batch to r%d after %d attempts over %s; last descriptor used: %s",
+			prevTok.Desc().RangeID, attempts, timeutil.Since(tRetryBegin).Round(time.Millisecond),
+			prevTok.Desc()))
+	}
+
 	return response{pErr: pErr}
 }
 
@@ -1959,7 +2169,7 @@ func (ds *DistSender) sendToReplicas(
 		// First order by latency, then move the leaseholder to the front of the
 		// list, if it is known.
 		if !ds.dontReorderReplicas {
-			replicas.OptimizeReplicaOrder(ds.getNodeDescriptor(), ds.latencyFunc)
+			ds.orderReplicas(ctx, replicas)
 		}
 
 		idx := -1
@@ -1978,11 +2188,14 @@ func (ds *DistSender) sendToReplicas(
 	case roachpb.RoutingPolicy_NEAREST:
 		// Order by latency.
 		log.VEvent(ctx, 2, "routing to nearest replica; leaseholder not required")
-		replicas.OptimizeReplicaOrder(ds.getNodeDescriptor(), ds.latencyFunc)
+		ds.orderReplicas(ctx, replicas)
 
 	default:
 		log.Fatalf(ctx, "unknown routing policy: %s", ba.RoutingPolicy)
 	}
+	if log.ExpensiveLogEnabled(ctx, 2) {
+		log.VEventf(ctx, 2, "r%d: replica order: %s", desc.RangeID, replicas.Descriptors())
+	}
 
 	opts := SendOptions{
 		class:                  rpc.ConnectionClassForKey(desc.RSpan().Key),
@@ -2071,7 +2284,12 @@ func (ds *DistSender) sendToReplicas(
 
 			ExplicitlyRequested: ba.ClientRangeInfo.ExplicitlyRequested,
 		}
-		br, err = transport.SendNext(ctx, ba)
+		if hedgeReplica, ok := ds.hedgeTarget(ba, routing, replicas, curReplica); first && ok {
+			br, err = ds.sendHedged(
+				ctx, ba, len(desc.Replicas().Descriptors()), transport, opts, hedgeReplica)
+		} else {
+			br, err = transport.SendNext(ctx, ba)
+		}
 		ds.maybeIncrementErrCounters(br, err)
 
 		if err != nil {
@@ -2200,8 +2418,29 @@ func (ds *DistSender) sendToReplicas(
 				// We'll try other replicas which typically gives us the leaseholder, either
 				// via the NotLeaseHolderError or nil error paths, both of which update the
 				// leaseholder in the range cache.
+				//
+				// If the replica is the one we had cached as the leaseholder though,
+				// the cached entry is certainly stale: a leaseholder can't be missing
+				// its range. Evict it so that other requests don't keep being routed
+				// to it while we look for the range elsewhere.
+				if lh := routing.Leaseholder(); lh != nil && *lh == curReplica {
+					ds.rangeCache.EvictByRangeID(ctx, tErr.RangeID)
+				} else if routing.Valid() {
+					// Otherwise we can't tell a removed replica from one that's still
+					// waiting for its snapshot, and the error doesn't carry a descriptor
+					// we could use. If the next replica returns an error too, nothing
+					// would correct a stale entry, so re-validate it in the background.
+					ds.rangeCache.RefreshAsync(ctx, routing.Desc())
+				}
 			case *roachpb.NotLeaseHolderError:
 				ds.metrics.NotLeaseHolderErrCount.Inc(1)
+				// If the replica that returned the error knows of a newer descriptor
+				// than ours, our cached descriptor is stale. The error carries the
+				// newer one, so put it in the cache right away; the lease update
+				// below then syncs the routing token to it.
+				if routing.Valid() && tErr.RangeDesc.Generation > routing.Desc().Generation {
+					ds.rangeCache.UpdateDescriptor(ctx, &tErr.RangeDesc)
+				}
 				// If we got some lease information, we use it. If not, we loop around
 				// and try the next replica.
 				if tErr.Lease != nil || tErr.LeaseHolder != nil {
@@ -2285,6 +2524,148 @@ func (ds *DistSender) sendToReplicas(
 	}
 }
 
+// orderReplicas sorts replicas in the order in which they should be tried,
+// using the configured ReplicaSlicePolicy if there is one.
+func (ds *DistSender) orderReplicas(ctx context.Context, replicas ReplicaSlice) {
+	if ds.replicaSlicePolicy != nil {
+		ds.replicaSlicePolicy.OrderReplicas(ctx, ds.getNodeDescriptor(), replicas)
+		return
+	}
+	replicas.OptimizeReplicaOrder(ds.getNodeDescriptor(), ds.latencyFunc)
+}
+
+// hedgeTarget returns the replica to which a duplicate of ba should be sent if
+// the RPC to curReplica is slow. Only read-only batches that can be served by
+// any replica are hedged, and only when kv.dist_sender.hedged_reads.delay is
+// set.
+func (ds *DistSender) hedgeTarget(
+	ba roachpb.BatchRequest,
+	routing rangecache.EvictionToken,
+	replicas ReplicaSlice,
+	curReplica roachpb.ReplicaDescriptor,
+) (ReplicaInfo, bool) {
+	if ba.RoutingPolicy != roachpb.RoutingPolicy_NEAREST || !ba.IsReadOnly() ||
+		hedgedReadDelay.Get(&ds.st.SV) == 0 {
+		return ReplicaInfo{}, false
+	}
+	// The replicas are ordered by latency, so the first one that's not the
+	// replica we're about to send to is the next-best choice.
+	for _, r := range replicas {
+		if r.ReplicaDescriptor == curReplica {
+			continue
+		}
+		if _, ok := routing.Desc().GetReplicaDescriptorByID(r.ReplicaID); !ok {
+			continue
+		}
+		return r, true
+	}
+	return ReplicaInfo{}, false
+}
+
+// sendHedged sends ba to the transport's next replica, like
+// transport.SendNext(). If no response arrives within
+// kv.dist_sender.hedged_reads.delay, a duplicate of ba is sent to
+// hedgeReplica. If the duplicate succeeds first, the original RPC is canceled
+// and the duplicate's response is returned. Otherwise, the duplicate is
+// canceled and the original RPC's result is returned, so that errors are
+// always attributed to the transport's replica by the caller.
+//
+// The original RPC is sent on the calling goroutine because transports are not
+// thread-safe; the duplicate uses a transport of its own.
+//
+// The duplicate is charged to the tenant's KV interceptor like any other RPC.
+// If its response is returned, the caller accounts for it as usual; otherwise,
+// a successful duplicate response is accounted for here.
+func (ds *DistSender) sendHedged(
+	ctx context.Context,
+	ba roachpb.BatchRequest,
+	numReplicas int,
+	transport Transport,
+	opts SendOptions,
+	hedgeReplica ReplicaInfo,
+) (*roachpb.BatchResponse, error) {
+	type result struct {
+		br  *roachpb.BatchResponse
+		err error
+	}
+	primaryCtx, cancelPrimary := context.WithCancel(ctx)
+	defer cancelPrimary()
+	hedgeCtx, cancelHedge := context.WithCancel(ctx)
+	defer cancelHedge()
+
+	// hedgeC receives exactly one result once the timer has fired.
+	hedgeC := make(chan result, 1)
+	timer := time.AfterFunc(hedgedReadDelay.Get(&ds.st.SV), func() {
+		if err := ds.rpcContext.Stopper.RunAsyncTaskEx(
+			hedgeCtx,
+			stop.TaskOpts{
+				TaskName: "kv.DistSender: sending hedged read",
+				SpanOpt:  stop.ChildSpan,
+			},
+			func(ctx context.Context) {
+				if ds.kvInterceptor != nil {
+					if err := ds.kvInterceptor.OnRequestWait(ctx); err != nil {
+						hedgeC <- result{err: err}
+						return
+					}
+				}
+				// The original RPC might have completed in the meantime.
+				if err := ctx.Err(); err != nil {
+					hedgeC <- result{err: err}
+					return
+				}
+				hedgeTransport, err := ds.transportFactory(
+					opts, ds.nodeDialer, ReplicaSlice{hedgeReplica})
+				if err != nil {
+					hedgeC <- result{err: err}
+					return
+				}
+				log.VEventf(ctx, 2, "r%d: sending hedged read to %s", ba.RangeID, hedgeReplica)
+				ds.metrics.HedgedReadCount.Inc(1)
+				br, err := hedgeTransport.SendNext(ctx, ba)
+				hedgeTransport.Release()
+				if err == nil && br.Error == nil {
+					cancelPrimary()
+				}
+				hedgeC <- result{br: br, err: err}
+			},
+		); err != nil {
+			hedgeC <- result{err: err}
+		}
+	})
+
+	br, err := transport.SendNext(primaryCtx, ba)
+	if timer.Stop() {
+		// The duplicate was never sent.
+		return br, err
+	}
+	if err == nil && br.Error == nil {
+		// Cancel the duplicate and wait for it, so that its response is accounted
+		// for if it made it back nonetheless.
+		cancelHedge()
+		if res := <-hedgeC; ds.kvInterceptor != nil && res.err == nil && res.br.Error == nil {
+			reqInfo := tenantcostmodel.MakeRequestInfo(&ba, numReplicas)
+			respInfo := tenantcostmodel.MakeResponseInfo(res.br, !reqInfo.IsWrite())
+			if err := ds.kvInterceptor.OnResponseWait(ctx, reqInfo, respInfo); err != nil {
+				return nil, err
+			}
+		}
+		return br, nil
+	}
+	// The original RPC failed, either on its own or because the duplicate
+	// succeeded and canceled it. Either way, the duplicate's result is the
+	// better one to use if it succeeds.
+	select {
+	case res := <-hedgeC:
+		if res.err == nil && res.br.Error == nil {
+			ds.metrics.HedgedReadWins.Inc(1)
+			return res.br, nil
+		}
+	case <-ctx.Done():
+	}
+	return br, err
+}
+
 func (ds *DistSender) maybeIncrementErrCounters(br *roachpb.BatchResponse, err error) {
 	if err == nil && br.Error == nil {
 		return
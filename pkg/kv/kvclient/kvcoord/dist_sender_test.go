@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/gossip/simulation"
@@ -48,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/errors/errutil"
+	"github.com/cockroachdb/logtags"
 	"github.com/cockroachdb/redact"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -442,7 +444,7 @@ func TestSendRPCOrder(t *testing.T) {
 type MockRangeDescriptorDB func(roachpb.RKey, bool) (rs, preRs []roachpb.RangeDescriptor, err error)
 
 func (mdb MockRangeDescriptorDB) RangeLookup(
-	ctx context.Context, key roachpb.RKey, useReverseScan bool,
+	ctx context.Context, key roachpb.RKey, _ int64, useReverseScan bool,
 ) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error) {
 	return mdb(key, useReverseScan)
 }
@@ -467,7 +469,10 @@ func (mdb MockRangeDescriptorDB) withMetaRecursion(
 	return func(key roachpb.RKey, useReverseScan bool) (rs, preRs []roachpb.RangeDescriptor, err error) {
 		metaKey := keys.RangeMetaKey(key)
 		if !metaKey.Equal(roachpb.RKeyMin) {
-			_, err := rdc.LookupWithEvictionToken(context.Background(), metaKey, rangecache.EvictionToken{}, useReverseScan)
+			_, err := rdc.LookupWithEvictionToken(
+				context.Background(), metaKey, rangecache.EvictionToken{}, useReverseScan,
+				rangecache.DefaultLookupOptions(),
+			)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -575,6 +580,59 @@ func TestImmutableBatchArgs(t *testing.T) {
 	}
 }
 
+// TestDistSenderSharedRangeCache verifies that DistSenders can share a range
+// descriptor cache, in which case a range looked up by one of them doesn't need
+// to be looked up again by the others.
+func TestDistSenderSharedRangeCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	g := makeGossip(t, stopper, rpcContext)
+	var testFn simpleSendFn = func(
+		_ context.Context, args roachpb.BatchRequest,
+	) (*roachpb.BatchResponse, error) {
+		return args.CreateReply(), nil
+	}
+	var numLookups int32
+	rDB := MockRangeDescriptorDB(func(key roachpb.RKey, useReverseScan bool) (
+		[]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error,
+	) {
+		atomic.AddInt32(&numLookups, 1)
+		return defaultMockRangeDescriptorDB(key, useReverseScan)
+	})
+
+	cfg := DistSenderConfig{
+		AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:      clock,
+		NodeDescs:  g,
+		RPCContext: rpcContext,
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: adaptSimpleTransport(testFn),
+		},
+		RangeDescriptorDB: rDB,
+		NodeDialer:        nodedialer.New(rpcContext, gossip.AddressResolver(g)),
+		Settings:          cluster.MakeTestingClusterSettings(),
+	}
+	ds1 := NewDistSender(cfg)
+	cfg.RangeDescriptorDB = nil
+	cfg.RangeDescriptorCache = ds1.RangeDescriptorCache()
+	ds2 := NewDistSender(cfg)
+	require.Same(t, ds1.RangeDescriptorCache(), ds2.RangeDescriptorCache())
+
+	get := roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */)
+	_, pErr := kv.SendWrapped(ctx, ds2, get)
+	require.Nil(t, pErr)
+	require.Equal(t, int32(1), atomic.LoadInt32(&numLookups))
+	_, pErr = kv.SendWrapped(ctx, ds1, get)
+	require.Nil(t, pErr)
+	require.Equal(t, int32(1), atomic.LoadInt32(&numLookups))
+}
+
 // TestRetryOnNotLeaseHolderError verifies that the DistSender correctly updates
 // the leaseholder in the range cache and retries when receiving a
 // NotLeaseHolderError.
@@ -1146,11 +1204,10 @@ func TestDistSenderIgnoresNLHEBasedOnOldRangeGeneration(t *testing.T) {
 	expectedCalls = append(expectedCalls, roachpb.NodeID(1))
 	require.Equal(t, expectedCalls, calls)
 
-	require.Regexp(
-		t,
-		"backing off due to .* stale info",
-		finishAndGetRecording().String(),
-	)
+	rec := finishAndGetRecording().String()
+	require.Regexp(t, "backing off due to .* stale info", rec)
+	// The replica order is traced, with the cached leaseholder first.
+	require.Regexp(t, `r1: replica order: \S*\(n2,s2\):2`, rec)
 }
 
 func TestDistSenderRetryOnTransportErrors(t *testing.T) {
@@ -1411,10 +1468,141 @@ func TestRetryOnDescriptorLookupError(t *testing.T) {
 	}
 }
 
-// TestEvictOnFirstRangeGossip verifies that we evict the first range
-// descriptor from the descriptor cache when a gossip update is received for
-// the first range.
-func TestEvictOnFirstRangeGossip(t *testing.T) {
+// prefetchRecordingDB is a RangeDescriptorDB that records the prefetch window
+// requested by its most recent range lookup.
+type prefetchRecordingDB struct {
+	MockRangeDescriptorDB
+	lastPrefetch int64
+}
+
+func (db *prefetchRecordingDB) RangeLookup(
+	ctx context.Context, key roachpb.RKey, prefetchNum int64, useReverseScan bool,
+) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error) {
+	atomic.StoreInt64(&db.lastPrefetch, prefetchNum)
+	return db.MockRangeDescriptorDB.RangeLookup(ctx, key, prefetchNum, useReverseScan)
+}
+
+// TestDistSenderPointLookupPrefetch verifies that the range lookups performed
+// for batches addressing a single key use a prefetch window of one, while the
+// lookups performed for batches spanning keys use a larger one.
+func TestDistSenderPointLookupPrefetch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	g := makeGossip(t, stopper, rpcContext)
+	db := &prefetchRecordingDB{MockRangeDescriptorDB: defaultMockRangeDescriptorDB}
+	ds := NewDistSender(DistSenderConfig{
+		AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:      clock,
+		NodeDescs:  g,
+		RPCContext: rpcContext,
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: adaptSimpleTransport(stubRPCSendFn),
+		},
+		RangeDescriptorDB: db,
+		NodeDialer:        nodedialer.New(rpcContext, gossip.AddressResolver(g)),
+		Settings:          cluster.MakeTestingClusterSettings(),
+	})
+
+	atomic.StoreInt64(&db.lastPrefetch, -1)
+	_, pErr := kv.SendWrapped(ctx, ds, roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */))
+	require.Nil(t, pErr)
+	require.Equal(t, int64(pointLookupPrefetch), atomic.LoadInt64(&db.lastPrefetch))
+
+	ds.rangeCache.Clear()
+	atomic.StoreInt64(&db.lastPrefetch, -1)
+	_, pErr = kv.SendWrapped(ctx, ds, roachpb.NewScan(roachpb.Key("a"), roachpb.Key("b"), false /* forUpdate */))
+	require.Nil(t, pErr)
+	require.Less(t, int64(pointLookupPrefetch), atomic.LoadInt64(&db.lastPrefetch))
+}
+
+// TestDistSenderVersionGatedRequests verifies that the DistSender rejects
+// requests using features that aren't enabled by the active cluster version.
+func TestDistSenderVersionGatedRequests(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	g := makeGossip(t, stopper, rpcContext)
+
+	testCases := []struct {
+		name    string
+		version clusterversion.Key
+		req     roachpb.Request
+		expErr  string
+	}{
+		{
+			name:    "bounded increment",
+			version: clusterversion.IncrementBounds,
+			req: &roachpb.IncrementRequest{
+				RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a")},
+				Increment:     1,
+				Bounds:        &roachpb.IncrementBounds{Min: 0, Max: 10},
+			},
+			expErr: "bounded increments require cluster version",
+		},
+		{
+			name:    "filtered scan",
+			version: clusterversion.FilteredScans,
+			req: &roachpb.ScanRequest{
+				RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")},
+				Filter:        &roachpb.ScanFilter{KeyRegexp: "a"},
+			},
+			expErr: "filtered scans require cluster version",
+		},
+		{
+			name:    "filtered reverse scan",
+			version: clusterversion.FilteredScans,
+			req: &roachpb.ReverseScanRequest{
+				RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")},
+				Filter:        &roachpb.ScanFilter{KeyRegexp: "a"},
+			},
+			expErr: "filtered scans require cluster version",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.RunTrueAndFalse(t, "active", func(t *testing.T, active bool) {
+				v := clusterversion.ByKey(tc.version)
+				if !active {
+					v = clusterversion.ByKey(tc.version - 1)
+				}
+				ds := NewDistSender(DistSenderConfig{
+					AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+					Clock:      clock,
+					NodeDescs:  g,
+					RPCContext: rpcContext,
+					TestingKnobs: ClientTestingKnobs{
+						TransportFactory: adaptSimpleTransport(stubRPCSendFn),
+					},
+					RangeDescriptorDB: defaultMockRangeDescriptorDB,
+					NodeDialer:        nodedialer.New(rpcContext, gossip.AddressResolver(g)),
+					Settings:          cluster.MakeTestingClusterSettingsWithVersions(v, v, true),
+				})
+				_, pErr := kv.SendWrapped(ctx, ds, tc.req)
+				if active {
+					require.Nil(t, pErr)
+				} else {
+					require.True(t, testutils.IsPError(pErr, tc.expErr), "unexpected error: %v", pErr)
+				}
+			})
+		})
+	}
+}
+
+// TestUpdateOnFirstRangeGossip verifies that when a gossip update is received
+// for the first range, the cached first range descriptor is replaced in place
+// with the gossiped one, rather than evicted and looked up again.
+func TestUpdateOnFirstRangeGossip(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
 
@@ -1478,18 +1666,21 @@ func TestEvictOnFirstRangeGossip(t *testing.T) {
 	anyKey := roachpb.Key("anything")
 	rAnyKey := keys.MustAddr(anyKey)
 
-	call := func() {
-		if _, err := ds.rangeCache.LookupWithEvictionToken(
+	call := func() rangecache.EvictionToken {
+		tok, err := ds.rangeCache.LookupWithEvictionToken(
 			context.Background(), rAnyKey, rangecache.EvictionToken{}, false,
-		); err != nil {
+			rangecache.DefaultLookupOptions(),
+		)
+		if err != nil {
 			t.Fatal(err)
 		}
+		return tok
 	}
 
 	// Perform multiple calls and check that the first range is only looked up
 	// once, with subsequent calls hitting the cache.
 	//
-	// This potentially races with the cache-evicting gossip callback on the
+	// This potentially races with the cache-updating gossip callback on the
 	// first range, so it is important that the first range descriptor's state
 	// in gossip is stable from this point forward.
 	for i := 0; i < 3; i++ {
@@ -1504,15 +1695,18 @@ func TestEvictOnFirstRangeGossip(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// Once Gossip fires the callbacks, we should see a cache eviction and thus,
-	// a new cache hit.
+	// Once Gossip fires the callbacks, we should see the gossiped descriptor in
+	// the cache, without it having been looked up again.
 	testutils.SucceedsSoon(t, func() error {
-		call()
-		if exp, act := int32(2), atomic.LoadInt32(&numFirstRange); exp != act {
-			return errors.Errorf("expected %d first range lookups, got %d", exp, act)
+		tok := call()
+		if exp, act := roachpb.RangeGeneration(1), tok.Desc().Generation; exp != act {
+			return errors.Errorf("expected cached generation %d, got %d", exp, act)
 		}
 		return nil
 	})
+	if num := atomic.LoadInt32(&numFirstRange); num != 1 {
+		t.Fatalf("expected one first range lookup, got %d", num)
+	}
 }
 
 func TestEvictCacheOnError(t *testing.T) {
@@ -2218,13 +2412,11 @@ func TestSendRPCRangeNotFoundError(t *testing.T) {
 		seen[ba.Replica.ReplicaID] = struct{}{}
 		if len(seen) <= 2 {
 			if len(seen) == 1 {
-				// Pretend that this replica is the leaseholder in the cache to verify
-				// that the response evicts it.
-				rng := ds.rangeCache.GetCached(ctx, descriptor.StartKey, false /* inverse */)
-				ds.rangeCache.Insert(ctx, roachpb.RangeInfo{
-					Desc:  *rng.Desc(),
-					Lease: roachpb.Lease{Replica: ba.Replica},
-				})
+				require.Equal(t, descriptor.InternalReplicas[0], ba.Replica)
+			} else {
+				// The RangeNotFoundError returned by the cached leaseholder evicted
+				// the range's descriptor.
+				require.Nil(t, ds.rangeCache.GetCached(ctx, descriptor.StartKey, false /* inverted */))
 			}
 			br.Error = roachpb.NewError(roachpb.NewRangeNotFoundError(ba.RangeID, ba.Replica.StoreID))
 			return br, nil
@@ -2251,6 +2443,12 @@ func TestSendRPCRangeNotFoundError(t *testing.T) {
 		Settings:          cluster.MakeTestingClusterSettings(),
 	}
 	ds = NewDistSender(cfg)
+	// Pretend that the first replica is the leaseholder in the cache to verify
+	// that the response evicts it.
+	ds.rangeCache.Insert(ctx, roachpb.RangeInfo{
+		Desc:  descriptor,
+		Lease: roachpb.Lease{Replica: descriptor.InternalReplicas[0]},
+	})
 	get := roachpb.NewGet(roachpb.Key("b"), false /* forUpdate */)
 	_, err := kv.SendWrapped(ctx, ds, get)
 	if err != nil {
@@ -2262,6 +2460,202 @@ func TestSendRPCRangeNotFoundError(t *testing.T) {
 	require.Equal(t, leaseholderStoreID, rng.Lease().Replica.StoreID)
 }
 
+// TestDistSenderNotLeaseHolderErrorUpdatesDescriptor verifies that a
+// NotLeaseHolderError carrying a newer descriptor than the cached one updates
+// the cache with that descriptor right away, without a range lookup.
+func TestDistSenderNotLeaseHolderErrorUpdatesDescriptor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	ns := &mockNodeStore{nodes: []roachpb.NodeDescriptor{
+		{NodeID: 1, Address: util.UnresolvedAddr{}},
+		{NodeID: 2, Address: util.UnresolvedAddr{}},
+		{NodeID: 3, Address: util.UnresolvedAddr{}},
+	}}
+
+	staleDesc := roachpb.RangeDescriptor{
+		RangeID:    roachpb.RangeID(1),
+		Generation: 1,
+		StartKey:   roachpb.RKeyMin,
+		EndKey:     roachpb.RKeyMax,
+		InternalReplicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2},
+			{NodeID: 3, StoreID: 3, ReplicaID: 3},
+		},
+	}
+	newDesc := roachpb.RangeDescriptor{
+		RangeID:    roachpb.RangeID(1),
+		Generation: 2,
+		StartKey:   roachpb.RKeyMin,
+		EndKey:     roachpb.RKeyMax,
+		InternalReplicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2},
+		},
+	}
+	newLease := roachpb.Lease{Replica: newDesc.InternalReplicas[1], Sequence: 2}
+
+	var calls int
+	transportFn := func(_ context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
+		br := &roachpb.BatchResponse{}
+		switch calls {
+		case 0:
+			require.Equal(t, staleDesc.InternalReplicas[0], ba.Replica)
+			br.Error = roachpb.NewError(&roachpb.NotLeaseHolderError{
+				RangeDesc: newDesc,
+				Lease:     &newLease,
+			})
+		case 1:
+			require.Equal(t, newLease.Replica, ba.Replica)
+			br = ba.CreateReply()
+		default:
+			t.Fatal("unexpected")
+		}
+		calls++
+		return br, nil
+	}
+
+	var rangeLookups int32
+	cfg := DistSenderConfig{
+		AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:      clock,
+		NodeDescs:  ns,
+		RPCContext: rpcContext,
+		RangeDescriptorDB: MockRangeDescriptorDB(func(key roachpb.RKey, reverse bool) (
+			[]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error,
+		) {
+			atomic.AddInt32(&rangeLookups, 1)
+			return []roachpb.RangeDescriptor{newDesc}, nil, nil
+		}),
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory:    adaptSimpleTransport(transportFn),
+			DontReorderReplicas: true,
+		},
+		Settings: cluster.MakeTestingClusterSettings(),
+	}
+	ds := NewDistSender(cfg)
+	ds.rangeCache.Insert(ctx, roachpb.RangeInfo{Desc: staleDesc})
+
+	get := roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */)
+	_, pErr := kv.SendWrapped(ctx, ds, get)
+	require.NoError(t, pErr.GoError())
+	require.Equal(t, 2, calls)
+
+	rng := ds.rangeCache.GetCached(ctx, roachpb.RKeyMin, false /* inverted */)
+	require.NotNil(t, rng)
+	require.Equal(t, newDesc, *rng.Desc())
+	require.Equal(t, newLease.Replica, rng.Lease().Replica)
+	require.Zero(t, atomic.LoadInt32(&rangeLookups))
+}
+
+// TestDistSenderRangeNotFoundErrorRefreshesDescriptor verifies that a
+// RangeNotFoundError from a replica that isn't the cached leaseholder triggers
+// a background refresh of the cached descriptor. The error doesn't carry a
+// descriptor, and when the next replica returns an error the response doesn't
+// carry any range info either, so without the refresh the stale descriptor
+// (still listing the removed replica) would stay in the cache.
+func TestDistSenderRangeNotFoundErrorRefreshesDescriptor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	ns := &mockNodeStore{nodes: []roachpb.NodeDescriptor{
+		{NodeID: 1, Address: util.UnresolvedAddr{}},
+		{NodeID: 2, Address: util.UnresolvedAddr{}},
+		{NodeID: 3, Address: util.UnresolvedAddr{}},
+	}}
+
+	staleDesc := roachpb.RangeDescriptor{
+		RangeID:    roachpb.RangeID(1),
+		Generation: 1,
+		StartKey:   roachpb.RKeyMin,
+		EndKey:     roachpb.RKeyMax,
+		InternalReplicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2},
+			{NodeID: 3, StoreID: 3, ReplicaID: 3},
+		},
+	}
+	// Replica 1 has been removed from the range.
+	newDesc := roachpb.RangeDescriptor{
+		RangeID:    roachpb.RangeID(1),
+		Generation: 2,
+		StartKey:   roachpb.RKeyMin,
+		EndKey:     roachpb.RKeyMax,
+		InternalReplicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 2, StoreID: 2, ReplicaID: 2},
+			{NodeID: 3, StoreID: 3, ReplicaID: 3},
+		},
+	}
+
+	var calls int
+	transportFn := func(_ context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
+		br := &roachpb.BatchResponse{}
+		switch calls {
+		case 0:
+			require.Equal(t, staleDesc.InternalReplicas[0], ba.Replica)
+			br.Error = roachpb.NewError(roachpb.NewRangeNotFoundError(ba.RangeID, ba.Replica.StoreID))
+		case 1:
+			require.Equal(t, staleDesc.InternalReplicas[1], ba.Replica)
+			br.Error = roachpb.NewError(&roachpb.ConditionFailedError{})
+		default:
+			t.Fatal("unexpected")
+		}
+		calls++
+		return br, nil
+	}
+
+	var rangeLookups int32
+	cfg := DistSenderConfig{
+		AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:      clock,
+		NodeDescs:  ns,
+		RPCContext: rpcContext,
+		RangeDescriptorDB: MockRangeDescriptorDB(func(key roachpb.RKey, reverse bool) (
+			[]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error,
+		) {
+			atomic.AddInt32(&rangeLookups, 1)
+			return []roachpb.RangeDescriptor{newDesc}, nil, nil
+		}),
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory:    adaptSimpleTransport(transportFn),
+			DontReorderReplicas: true,
+		},
+		Settings: cluster.MakeTestingClusterSettings(),
+	}
+	ds := NewDistSender(cfg)
+	ds.rangeCache.Insert(ctx, roachpb.RangeInfo{Desc: staleDesc})
+
+	get := roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */)
+	_, pErr := kv.SendWrapped(ctx, ds, get)
+	require.IsType(t, &roachpb.ConditionFailedError{}, pErr.GetDetail())
+	require.Equal(t, 2, calls)
+
+	testutils.SucceedsSoon(t, func() error {
+		rng := ds.rangeCache.GetCached(ctx, roachpb.RKeyMin, false /* inverted */)
+		if rng == nil {
+			return errors.New("range not cached")
+		}
+		if gen := rng.Desc().Generation; gen != newDesc.Generation {
+			return errors.Errorf("expected generation %d, got %d", newDesc.Generation, gen)
+		}
+		return nil
+	})
+	require.Equal(t, int32(1), atomic.LoadInt32(&rangeLookups))
+}
+
 // TestGetNodeDescriptor checks that the Node descriptor automatically gets
 // looked up from Gossip.
 func TestGetNodeDescriptor(t *testing.T) {
@@ -5067,3 +5461,305 @@ func TestDistSenderRPCMetrics(t *testing.T) {
 	require.Equal(t, ds.metrics.ErrCounts[roachpb.NotLeaseHolderErrType].Count(), int64(1))
 	require.Equal(t, ds.metrics.ErrCounts[roachpb.ConditionFailedErrType].Count(), int64(1))
 }
+
+// TestDistSenderHedgedReads verifies that a slow follower-readable batch is
+// also sent to the next replica once kv.dist_sender.hedged_reads.delay has
+// elapsed, and that the first successful response is used.
+func TestDistSenderHedgedReads(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	ns := &mockNodeStore{nodes: []roachpb.NodeDescriptor{
+		{NodeID: 1, Address: util.UnresolvedAddr{}},
+		{NodeID: 2, Address: util.UnresolvedAddr{}},
+	}}
+
+	var desc = roachpb.RangeDescriptor{
+		RangeID:    roachpb.RangeID(1),
+		Generation: 1,
+		StartKey:   roachpb.RKeyMin,
+		EndKey:     roachpb.RKeyMax,
+		InternalReplicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2},
+		},
+	}
+
+	// The first RPC hangs until it is canceled; any other RPC succeeds.
+	var calls int32
+	var slowReplica, fastReplica atomic.Value
+	var transportFn = func(ctx context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			slowReplica.Store(ba.Replica)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		fastReplica.Store(ba.Replica)
+		return ba.CreateReply(), nil
+	}
+
+	st := cluster.MakeTestingClusterSettings()
+	hedgedReadDelay.Override(ctx, &st.SV, 10*time.Millisecond)
+	cfg := DistSenderConfig{
+		AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:      clock,
+		NodeDescs:  ns,
+		RPCContext: rpcContext,
+		RangeDescriptorDB: MockRangeDescriptorDB(func(key roachpb.RKey, reverse bool) (
+			[]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error,
+		) {
+			return nil, nil, errors.New("range desc db unexpectedly used")
+		}),
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: adaptSimpleTransport(transportFn),
+		},
+		Settings: st,
+	}
+
+	ds := NewDistSender(cfg)
+	ds.rangeCache.Insert(ctx, roachpb.RangeInfo{Desc: desc})
+	var ba roachpb.BatchRequest
+	ba.RoutingPolicy = roachpb.RoutingPolicy_NEAREST
+	get := &roachpb.GetRequest{}
+	get.Key = roachpb.Key("a")
+	ba.Add(get)
+
+	_, pErr := ds.Send(ctx, ba)
+	require.Nil(t, pErr)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	require.NotEqual(t, slowReplica.Load(), fastReplica.Load())
+	require.Equal(t, int64(1), ds.metrics.HedgedReadCount.Count())
+	require.Equal(t, int64(1), ds.metrics.HedgedReadWins.Count())
+}
+
+// TestDistSenderRetryBudget verifies that DistSender stops retrying a batch
+// whose replicas are all unreachable once RPCRetryBudget is exhausted.
+func TestDistSenderRetryBudget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	ns := &mockNodeStore{nodes: []roachpb.NodeDescriptor{
+		{NodeID: 1, Address: util.UnresolvedAddr{}},
+		{NodeID: 2, Address: util.UnresolvedAddr{}},
+	}}
+
+	var desc = roachpb.RangeDescriptor{
+		RangeID:    roachpb.RangeID(1),
+		Generation: 1,
+		StartKey:   roachpb.RKeyMin,
+		EndKey:     roachpb.RKeyMax,
+		InternalReplicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2},
+		},
+	}
+
+	var calls int32
+	var transportFn = func(_ context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errors.New("boom")
+	}
+
+	cfg := DistSenderConfig{
+		AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:      clock,
+		NodeDescs:  ns,
+		RPCContext: rpcContext,
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: adaptSimpleTransport(transportFn),
+		},
+		RangeDescriptorDB: mockRangeDescriptorDBForDescs(desc),
+		Settings:          cluster.MakeTestingClusterSettings(),
+		RPCRetryOptions: &retry.Options{
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+			Multiplier:     1,
+		},
+		RPCRetryBudget: 50 * time.Millisecond,
+	}
+	ds := NewDistSender(cfg)
+
+	get := roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */)
+	_, pErr := kv.SendWrapped(ctx, ds, get)
+	require.NotNil(t, pErr)
+	require.Regexp(t, "sending to all replicas failed; last error: boom", pErr)
+	// The error says what was tried.
+	require.Regexp(t, `failed to send batch to r1 after \d+ attempts over .*; last descriptor used: r1:`, pErr)
+	// Each attempt tries both replicas, and the budget allows for more than
+	// one attempt.
+	require.Greater(t, atomic.LoadInt32(&calls), int32(2))
+	require.Greater(t, ds.metrics.PartialBatchRetryCount.Count(), int64(0))
+}
+
+type rejectTagRateLimiter struct {
+	reject string
+	tags   []string
+}
+
+func (l *rejectTagRateLimiter) Wait(_ context.Context, tag string, _ *roachpb.BatchRequest) error {
+	l.tags = append(l.tags, tag)
+	if tag == l.reject {
+		return errors.Newf("rate limit exceeded for %s", tag)
+	}
+	return nil
+}
+
+// TestDistSenderRateLimiter verifies that the configured RequestRateLimiter is
+// consulted before a batch is sent with the tag identifying the batch's source,
+// and that a batch it rejects is not sent.
+func TestDistSenderRateLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	g := makeGossip(t, stopper, rpcContext)
+
+	var sent int
+	sendFn := func(_ context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
+		sent++
+		return ba.CreateReply(), nil
+	}
+	limiter := &rejectTagRateLimiter{reject: "job=42"}
+	cfg := DistSenderConfig{
+		AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:      clock,
+		NodeDescs:  g,
+		RPCContext: rpcContext,
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: adaptSimpleTransport(sendFn),
+		},
+		RangeDescriptorDB: defaultMockRangeDescriptorDB,
+		NodeDialer:        nodedialer.New(rpcContext, gossip.AddressResolver(g)),
+		Settings:          cluster.MakeTestingClusterSettings(),
+		RateLimiter:       limiter,
+	}
+	ds := NewDistSender(cfg)
+
+	get := roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */)
+	userCtx := logtags.AddTag(ctx, "user", "alice")
+	_, pErr := kv.SendWrappedWithAdmission(userCtx, ds, roachpb.Header{},
+		roachpb.AdmissionHeader{Source: roachpb.AdmissionHeader_FROM_SQL}, get)
+	require.Nil(t, pErr)
+	require.Equal(t, 1, sent)
+
+	// A job's tag takes precedence over the user running it.
+	jobCtx := logtags.AddTag(userCtx, "job", 42)
+	_, pErr = kv.SendWrappedWithAdmission(jobCtx, ds, roachpb.Header{},
+		roachpb.AdmissionHeader{Source: roachpb.AdmissionHeader_FROM_SQL}, get)
+	require.Regexp(t, "rate limit exceeded for job=42", pErr)
+	require.Equal(t, 1, sent)
+
+	// Without any of the tags, the batch is attributed to its admission source.
+	_, pErr = kv.SendWrappedWithAdmission(ctx, ds, roachpb.Header{},
+		roachpb.AdmissionHeader{Source: roachpb.AdmissionHeader_ROOT_KV}, get)
+	require.Nil(t, pErr)
+	require.Equal(t, 2, sent)
+
+	require.Equal(t, []string{"user=alice", "job=42", "source=ROOT_KV"}, limiter.tags)
+}
+
+// descendingNodeIDPolicy is a ReplicaSlicePolicy that orders replicas by
+// descending node ID.
+type descendingNodeIDPolicy struct{}
+
+func (descendingNodeIDPolicy) OrderReplicas(
+	_ context.Context, _ *roachpb.NodeDescriptor, replicas ReplicaSlice,
+) {
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].NodeID > replicas[j].NodeID
+	})
+}
+
+// TestDistSenderReplicaSlicePolicy verifies that a configured
+// ReplicaSlicePolicy decides the order in which replicas are tried, and that
+// a known leaseholder is still tried first when one is required.
+func TestDistSenderReplicaSlicePolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	ns := &mockNodeStore{nodes: []roachpb.NodeDescriptor{
+		{NodeID: 1, Address: util.UnresolvedAddr{}},
+		{NodeID: 2, Address: util.UnresolvedAddr{}},
+		{NodeID: 3, Address: util.UnresolvedAddr{}},
+	}}
+
+	var desc = roachpb.RangeDescriptor{
+		RangeID:    roachpb.RangeID(1),
+		Generation: 1,
+		StartKey:   roachpb.RKeyMin,
+		EndKey:     roachpb.RKeyMax,
+		InternalReplicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1, ReplicaID: 1},
+			{NodeID: 2, StoreID: 2, ReplicaID: 2},
+			{NodeID: 3, StoreID: 3, ReplicaID: 3},
+		},
+	}
+
+	var sentTo []roachpb.NodeID
+	transportFactory := func(
+		opts SendOptions, dialer *nodedialer.Dialer, replicas ReplicaSlice,
+	) (Transport, error) {
+		sentTo = nil
+		for _, r := range replicas {
+			sentTo = append(sentTo, r.NodeID)
+		}
+		return adaptSimpleTransport(stubRPCSendFn)(opts, dialer, replicas)
+	}
+
+	cfg := DistSenderConfig{
+		AmbientCtx: log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:      clock,
+		NodeDescs:  ns,
+		RPCContext: rpcContext,
+		RangeDescriptorDB: MockRangeDescriptorDB(func(key roachpb.RKey, reverse bool) (
+			[]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error,
+		) {
+			return nil, nil, errors.New("range desc db unexpectedly used")
+		}),
+		TestingKnobs: ClientTestingKnobs{
+			TransportFactory: transportFactory,
+		},
+		Settings:           cluster.MakeTestingClusterSettings(),
+		ReplicaSlicePolicy: descendingNodeIDPolicy{},
+	}
+	ds := NewDistSender(cfg)
+
+	for _, tc := range []struct {
+		routingPolicy roachpb.RoutingPolicy
+		exp           []roachpb.NodeID
+	}{
+		{routingPolicy: roachpb.RoutingPolicy_NEAREST, exp: []roachpb.NodeID{3, 2, 1}},
+		{routingPolicy: roachpb.RoutingPolicy_LEASEHOLDER, exp: []roachpb.NodeID{1, 3, 2}},
+	} {
+		t.Run(tc.routingPolicy.String(), func(t *testing.T) {
+			ds.rangeCache.Clear()
+			ds.rangeCache.Insert(ctx, roachpb.RangeInfo{
+				Desc:  desc,
+				Lease: roachpb.Lease{Replica: desc.InternalReplicas[0]},
+			})
+			get := roachpb.NewGet(roachpb.Key("a"), false /* forUpdate */)
+			_, pErr := kv.SendWrappedWith(ctx, ds, roachpb.Header{RoutingPolicy: tc.routingPolicy}, get)
+			require.Nil(t, pErr)
+			require.Equal(t, tc.exp, sentTo)
+		})
+	}
+}
@@ -33,8 +33,28 @@ type RangeIterator struct {
 	token rangecache.EvictionToken
 	init  bool
 	err   error
+	// prefetch is the number of range descriptors prefetched by the range
+	// lookups performed by the iterator. It starts out at
+	// rangecache.DefaultPrefetchCount, or at the window recorded in the
+	// rangecache.ScanPrefetch carried by the caller's context, and grows as the
+	// iterator keeps moving on to subsequent ranges, as scans over many ranges
+	// do.
+	prefetch int64
+	// pointLookup is set by callers that only need the range containing a
+	// single key. Lookups performed by such an iterator use
+	// pointLookupPrefetch.
+	pointLookup bool
 }
 
+const (
+	// maxRangeIteratorPrefetch caps the prefetch window of an iterator moving
+	// sequentially across ranges.
+	maxRangeIteratorPrefetch = 64
+	// pointLookupPrefetch is the prefetch window of the range lookups performed
+	// for a single key, which won't visit any subsequent ranges.
+	pointLookupPrefetch = 1
+)
+
 // MakeRangeIterator creates a new RangeIterator.
 func MakeRangeIterator(ds *DistSender) RangeIterator {
 	return RangeIterator{
@@ -172,6 +192,16 @@ func (ri *RangeIterator) Next(ctx context.Context) {
 	}
 }
 
+// movingOn returns whether key is past the range at which the iterator is
+// currently positioned, in the iterator's scan direction. The iterator must be
+// valid.
+func (ri *RangeIterator) movingOn(key roachpb.RKey) bool {
+	if ri.scanDir == Ascending {
+		return !key.Less(ri.Desc().EndKey)
+	}
+	return !ri.Desc().StartKey.Less(key)
+}
+
 // Seek positions the iterator at the specified key.
 func (ri *RangeIterator) Seek(ctx context.Context, key roachpb.RKey, scanDir ScanDirection) {
 	if log.HasSpanOrEvent(ctx) {
@@ -181,6 +211,28 @@ func (ri *RangeIterator) Seek(ctx context.Context, key roachpb.RKey, scanDir Sca
 		}
 		log.Eventf(ctx, "querying next range at %s%s", key, rev)
 	}
+	// Grow the prefetch window if we're moving on to a subsequent range in the
+	// same direction as before. Otherwise, start from the default window, or
+	// from the one the caller's previous scans have reached.
+	if ri.pointLookup {
+		ri.prefetch = pointLookupPrefetch
+	} else {
+		sp := rangecache.ScanPrefetchFromContext(ctx)
+		if ri.Valid() && scanDir == ri.scanDir && ri.movingOn(key) {
+			ri.prefetch *= 2
+			if ri.prefetch > maxRangeIteratorPrefetch {
+				ri.prefetch = maxRangeIteratorPrefetch
+			}
+			if sp != nil {
+				sp.Record(ri.prefetch)
+			}
+		} else {
+			ri.prefetch = rangecache.DefaultPrefetchCount
+			if sp != nil && sp.Window() > ri.prefetch {
+				ri.prefetch = sp.Window()
+			}
+		}
+	}
 	ri.scanDir = scanDir
 	ri.init = true // the iterator is now initialized
 	ri.err = nil   // clear any prior error
@@ -197,7 +249,8 @@ func (ri *RangeIterator) Seek(ctx context.Context, key roachpb.RKey, scanDir Sca
 	var err error
 	for r := retry.StartWithCtx(ctx, ri.ds.rpcRetryOptions); r.Next(); {
 		var rngInfo rangecache.EvictionToken
-		rngInfo, err = ri.ds.getRoutingInfo(ctx, ri.key, ri.token, ri.scanDir == Descending)
+		opts := rangecache.LookupOptions{PrefetchCount: ri.prefetch}
+		rngInfo, err = ri.ds.getRoutingInfo(ctx, ri.key, ri.token, ri.scanDir == Descending, opts)
 
 		// getRoutingInfo may fail retryably if, for example, the first
 		// range isn't available via Gossip. Assume that all errors at
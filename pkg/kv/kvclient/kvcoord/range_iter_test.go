@@ -13,9 +13,11 @@ package kvcoord
 import (
 	"context"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangecache"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -23,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/stretchr/testify/require"
 )
 
 var alphaRangeDescriptors []roachpb.RangeDescriptor
@@ -199,3 +202,75 @@ func TestRangeIterSeekReverse(t *testing.T) {
 		}
 	}
 }
+
+// TestRangeIterPrefetch verifies that an iterator's first range lookup
+// prefetches the default number of descriptors, that the prefetch window grows
+// as the iterator moves across ranges, that the window only carries over to
+// iterators serving the same caller, and that point lookups use a window of
+// one.
+func TestRangeIterPrefetch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	rpcContext := rpc.NewInsecureTestingContext(ctx, clock, stopper)
+	g := makeGossip(t, stopper, rpcContext)
+	db := &prefetchRecordingDB{MockRangeDescriptorDB: alphaRangeDescriptorDB}
+	ds := NewDistSender(DistSenderConfig{
+		AmbientCtx:        log.MakeTestingAmbientCtxWithNewTracer(),
+		Clock:             clock,
+		NodeDescs:         g,
+		RPCContext:        rpcContext,
+		RangeDescriptorDB: db,
+		Settings:          cluster.MakeTestingClusterSettings(),
+	})
+
+	// The first lookup, against a cold cache, uses the default window.
+	scanCtx := rangecache.WithScanPrefetch(ctx)
+	ri := MakeRangeIterator(ds)
+	ri.Seek(scanCtx, roachpb.RKey("c"), Ascending)
+	require.True(t, ri.Valid())
+	require.Equal(t, int64(rangecache.DefaultPrefetchCount), atomic.LoadInt64(&db.lastPrefetch))
+	require.Equal(t, int64(rangecache.DefaultPrefetchCount), ri.prefetch)
+
+	// Moving on to subsequent ranges grows the window.
+	for i := 0; i < 5; i++ {
+		prefetch := ri.prefetch
+		ri.Next(scanCtx)
+		require.True(t, ri.Valid())
+		exp := 2 * prefetch
+		if exp > maxRangeIteratorPrefetch {
+			exp = maxRangeIteratorPrefetch
+		}
+		require.Equal(t, exp, ri.prefetch)
+	}
+	require.Equal(t, int64(maxRangeIteratorPrefetch), ri.prefetch)
+	require.Equal(t, int64(maxRangeIteratorPrefetch),
+		rangecache.ScanPrefetchFromContext(scanCtx).Window())
+
+	// A new iterator serving the same caller, as used by the next batch of a
+	// paginated scan, starts from the window reached by the previous one.
+	ri2 := MakeRangeIterator(ds)
+	ri2.Seek(scanCtx, roachpb.RKey("m"), Ascending)
+	require.Equal(t, int64(maxRangeIteratorPrefetch), ri2.prefetch)
+
+	// Iterators serving other callers are unaffected.
+	ri3 := MakeRangeIterator(ds)
+	ri3.Seek(ctx, roachpb.RKey("m"), Ascending)
+	require.Equal(t, int64(rangecache.DefaultPrefetchCount), ri3.prefetch)
+	ri4 := MakeRangeIterator(ds)
+	ri4.Seek(rangecache.WithScanPrefetch(ctx), roachpb.RKey("m"), Ascending)
+	require.Equal(t, int64(rangecache.DefaultPrefetchCount), ri4.prefetch)
+
+	// Point lookups use a window of one, even when the caller's window is
+	// larger.
+	ds.rangeCache.Clear()
+	ri5 := MakeRangeIterator(ds)
+	ri5.pointLookup = true
+	ri5.Seek(scanCtx, roachpb.RKey("c"), Ascending)
+	require.True(t, ri5.Valid())
+	require.Equal(t, int64(pointLookupPrefetch), atomic.LoadInt64(&db.lastPrefetch))
+}
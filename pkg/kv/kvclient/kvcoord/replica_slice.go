@@ -236,6 +236,20 @@ func (rs ReplicaSlice) OptimizeReplicaOrder(
 	})
 }
 
+// ReplicaSlicePolicy orders the candidate replicas of a range before
+// DistSender tries them. It replaces the default latency and locality based
+// ordering performed by OptimizeReplicaOrder, e.g. to prefer replicas in the
+// same datacenter or to avoid nodes that are known to be draining.
+//
+// When a batch must be routed to the leaseholder, DistSender still moves the
+// known leaseholder to the front of the slice after the policy has run.
+type ReplicaSlicePolicy interface {
+	// OrderReplicas sorts replicas in place in the order in which they should
+	// be tried. nodeDesc is the descriptor of the current node; it can be nil
+	// if it is not known yet.
+	OrderReplicas(ctx context.Context, nodeDesc *roachpb.NodeDescriptor, replicas ReplicaSlice)
+}
+
 // Descriptors returns the ReplicaDescriptors inside the ReplicaSlice.
 func (rs ReplicaSlice) Descriptors() []roachpb.ReplicaDescriptor {
 	reps := make([]roachpb.ReplicaDescriptor, len(rs))
@@ -370,7 +370,8 @@ func sendBatch(
 		Desc:  *desc,
 		Lease: roachpb.Lease{},
 	})
-	routing, err := ds.getRoutingInfo(ctx, desc.StartKey, rangecache.EvictionToken{}, false /* useReverseScan */)
+	routing, err := ds.getRoutingInfo(ctx, desc.StartKey, rangecache.EvictionToken{},
+		false /* useReverseScan */, rangecache.DefaultLookupOptions())
 	require.NoError(t, err)
 
 	return ds.sendToReplicas(ctx, roachpb.BatchRequest{}, routing, false /* withCommit */)
@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -22,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/logtags"
@@ -1390,6 +1392,30 @@ func (tc *TxnCoordSender) HasPerformedWrites() bool {
 	return tc.hasPerformedWritesLocked()
 }
 
+// Stats is part of the TxnSender interface.
+func (tc *TxnCoordSender) Stats() kv.TxnStats {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tp := &tc.interceptorAlloc.txnPipeliner
+	m := &tc.interceptorAlloc.txnMetricRecorder
+	stats := kv.TxnStats{
+		Restarts:       int32(tc.mu.txn.Epoch),
+		Refreshes:      tc.interceptorAlloc.txnSpanRefresher.refreshes,
+		IntentsWritten: m.intentsWritten,
+		BytesWritten:   m.bytesWritten,
+		LockSpans:      len(tp.lockFootprint.asSlice()) + tp.ifWrites.len(),
+	}
+	if m.txnStartNanos != 0 {
+		// Once the transaction is finalized, its duration no longer grows.
+		end := m.txnEndNanos
+		if end == 0 {
+			end = timeutil.Now().UnixNano()
+		}
+		stats.Duration = time.Duration(end - m.txnStartNanos)
+	}
+	return stats
+}
+
 func (tc *TxnCoordSender) hasPerformedReadsLocked() bool {
 	return !tc.interceptorAlloc.txnSpanRefresher.refreshFootprint.empty()
 }
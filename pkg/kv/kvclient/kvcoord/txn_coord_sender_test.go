@@ -1291,6 +1291,47 @@ func TestTxnRestartCount(t *testing.T) {
 	checkTxnMetrics(t, metrics, "restart txn", 0, 0, 1 /* aborts */, 1 /* restarts */)
 }
 
+// TestTxnStats verifies that the per-transaction statistics exposed through
+// Txn.Stats reflect the transaction's locks and refreshes.
+func TestTxnStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	readKey := []byte("read")
+	writeKey := []byte("write")
+	ctx := context.Background()
+
+	s, _, cleanupFn := setupMetricsTest(t)
+	defer cleanupFn()
+
+	txn := kv.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */)
+	require.Equal(t, kv.TxnStats{}, txn.Stats())
+	_, err := txn.Get(ctx, readKey)
+	require.NoError(t, err)
+
+	// Read the key that the transaction is about to write outside of the
+	// transaction, forcing the write to a higher timestamp. Since the read key
+	// is left untouched, the transaction can refresh to that timestamp on
+	// commit instead of restarting.
+	_, err = s.DB.Get(ctx, writeKey)
+	require.NoError(t, err)
+	require.NoError(t, txn.Put(ctx, writeKey, "value"))
+
+	stats := txn.Stats()
+	require.Equal(t, 1, stats.LockSpans)
+	require.Equal(t, int64(1), stats.IntentsWritten)
+	require.Greater(t, stats.BytesWritten, int64(0))
+	require.Zero(t, stats.Refreshes)
+
+	require.NoError(t, txn.Commit(ctx))
+	stats = txn.Stats()
+	require.Equal(t, int64(1), stats.Refreshes)
+	require.Zero(t, stats.Restarts)
+	require.Greater(t, stats.Duration, time.Duration(0))
+	// The duration stops growing once the transaction is finalized.
+	require.Equal(t, stats.Duration, txn.Stats().Duration)
+}
+
 func TestTxnDurations(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -31,8 +31,15 @@ type txnMetricRecorder struct {
 
 	txn            *roachpb.Transaction
 	txnStartNanos  int64
+	txnEndNanos    int64
 	onePCCommit    bool
 	parallelCommit bool
+
+	// intentsWritten and bytesWritten count the intents written by the
+	// transaction's successful requests and the size of those requests' writes.
+	// Writes are counted again if they are repeated in a later epoch.
+	intentsWritten int64
+	bytesWritten   int64
 }
 
 // SendLocked is part of the txnInterceptor interface.
@@ -48,6 +55,21 @@ func (m *txnMetricRecorder) SendLocked(
 		return br, pErr
 	}
 
+	for i, ru := range ba.Requests {
+		req := ru.GetInner()
+		if !roachpb.IsIntentWrite(req) {
+			continue
+		}
+		if swr, ok := req.(roachpb.SizedWriteRequest); ok {
+			m.bytesWritten += swr.WriteBytes()
+		}
+		if !roachpb.IsRange(req) {
+			m.intentsWritten++
+		} else if dr, ok := br.Responses[i].GetInner().(*roachpb.DeleteRangeResponse); ok {
+			m.intentsWritten += int64(len(dr.Keys))
+		}
+	}
+
 	if length := len(br.Responses); length > 0 {
 		if et := br.Responses[length-1].GetEndTxn(); et != nil {
 			// Check for 1-phase commit.
@@ -91,7 +113,8 @@ func (m *txnMetricRecorder) closeLocked() {
 	}
 
 	if m.txnStartNanos != 0 {
-		duration := timeutil.Now().UnixNano() - m.txnStartNanos
+		m.txnEndNanos = timeutil.Now().UnixNano()
+		duration := m.txnEndNanos - m.txnStartNanos
 		if duration >= 0 {
 			m.metrics.Durations.RecordValue(duration)
 		}
@@ -129,6 +129,10 @@ type txnSpanRefresher struct {
 	// canAutoRetry is set if the txnSpanRefresher is allowed to auto-retry.
 	canAutoRetry bool
 
+	// refreshes counts the successful refreshes performed by the transaction
+	// across all of its epochs. It is surfaced through TxnCoordSender.Stats.
+	refreshes int64
+
 	refreshSuccess                *metric.Counter
 	refreshFail                   *metric.Counter
 	refreshFailWithCondensedSpans *metric.Counter
@@ -486,6 +490,7 @@ func (sr *txnSpanRefresher) tryRefreshTxnSpans(
 	// Track the result of the refresh in metrics.
 	defer func() {
 		if err == nil {
+			sr.refreshes++
 			sr.refreshSuccess.Inc(1)
 		} else {
 			sr.refreshFail.Inc(1)
@@ -0,0 +1,51 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rangecache
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// HTML is exposed at /debug/range-cache. It lists every cached descriptor, in
+// key order, along with its cache key, the time it was inserted (or last
+// confirmed by a range lookup), and the number of lookups it served.
+func (rc *RangeCache) HTML() string {
+	sb := &strings.Builder{}
+	escape := func(s string) string {
+		return html.EscapeString(s)
+	}
+
+	rc.rangeCache.RLock()
+	defer rc.rangeCache.RUnlock()
+
+	now := rc.timeSource.Now()
+	fmt.Fprintf(sb, "<h4>Range descriptor cache (%d entries)</h4>", rc.rangeCache.cache.Len())
+	sb.WriteString("<table><tr>" +
+		"<th>key</th><th>descriptor</th><th>lease</th><th>cached at</th><th>hits</th>" +
+		"</tr>\n")
+	rc.rangeCache.cache.Do(func(k, v interface{}) bool {
+		e := v.(*CacheEntry)
+		var cachedAt string
+		if !e.cachedAt.IsZero() {
+			cachedAt = fmt.Sprintf("%s (%s ago)",
+				e.cachedAt.Truncate(time.Millisecond), now.Sub(e.cachedAt).Truncate(time.Millisecond))
+		}
+		fmt.Fprintf(sb, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%d</td></tr>\n",
+			escape(k.(rangeCacheKey).String()), escape(e.desc.String()), escape(e.lease.String()),
+			cachedAt, e.numHits())
+		return false
+	})
+	sb.WriteString("</table>")
+	return sb.String()
+}
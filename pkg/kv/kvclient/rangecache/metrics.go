@@ -0,0 +1,79 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rangecache
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+var (
+	metaRangeCacheHits = metric.Metadata{
+		Name:        "rangecache.hits",
+		Help:        "Number of range descriptor cache lookups served from the cache",
+		Measurement: "Lookups",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeCacheMisses = metric.Metadata{
+		Name: "rangecache.misses",
+		Help: `Number of range descriptor cache lookups not served from the cache
+
+A miss results in a meta range lookup, unless it is coalesced onto a lookup
+that is already in flight.`,
+		Measurement: "Lookups",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeCacheEvictions = metric.Metadata{
+		Name: "rangecache.evictions",
+		Help: `Number of range descriptor cache entries evicted
+
+This includes entries evicted because they were found to be stale as well as
+entries evicted to make room for new ones.`,
+		Measurement: "Entries",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeCachePrefetchAdds = metric.Metadata{
+		Name:        "rangecache.prefetch.adds",
+		Help:        "Number of prefetched range descriptors added to the range descriptor cache",
+		Measurement: "Entries",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRangeCacheLookupLatency = metric.Metadata{
+		Name:        "rangecache.lookup.latency",
+		Help:        "Latency of the meta range lookups performed on range descriptor cache misses",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+)
+
+// Metrics is the set of metrics for a RangeCache.
+type Metrics struct {
+	CacheHits     *metric.Counter
+	CacheMisses   *metric.Counter
+	Evictions     *metric.Counter
+	PrefetchAdds  *metric.Counter
+	LookupLatency *metric.Histogram
+}
+
+func makeMetrics() Metrics {
+	return Metrics{
+		CacheHits:     metric.NewCounter(metaRangeCacheHits),
+		CacheMisses:   metric.NewCounter(metaRangeCacheMisses),
+		Evictions:     metric.NewCounter(metaRangeCacheEvictions),
+		PrefetchAdds:  metric.NewCounter(metaRangeCachePrefetchAdds),
+		LookupLatency: metric.NewLatency(metaRangeCacheLookupLatency, base.DefaultHistogramWindowInterval()),
+	}
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (Metrics) MetricStruct() {}
+
+var _ metric.Struct = Metrics{}
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/biogo/store/llrb"
@@ -27,12 +28,15 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/quotapool"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil/singleflight"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/logtags"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 //go:generate mockgen -package=rangecachemock -destination=rangecachemock/mocks_generated.go . RangeDescriptorDB
@@ -60,9 +64,10 @@ type RangeDescriptorDB interface {
 	// RangeLookup takes a key to look up descriptors for. Two slices of range
 	// descriptors are returned. The first of these slices holds descriptors
 	// whose [startKey,endKey) spans contain the given key (possibly from
-	// intents), and the second holds prefetched adjacent descriptors.
+	// intents), and the second holds up to prefetchNum prefetched adjacent
+	// descriptors.
 	RangeLookup(
-		ctx context.Context, key roachpb.RKey, useReverseScan bool,
+		ctx context.Context, key roachpb.RKey, prefetchNum int64, useReverseScan bool,
 	) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error)
 
 	// FirstRange returns the descriptor for the first Range. This is the
@@ -71,13 +76,92 @@ type RangeDescriptorDB interface {
 	FirstRange() (*roachpb.RangeDescriptor, error)
 }
 
+// DefaultPrefetchCount is the number of range descriptors, besides the one
+// being looked up, that a range lookup prefetches unless the caller asks for a
+// different window.
+const DefaultPrefetchCount = 8
+
+// LookupOptions configure the range lookups performed on cache misses.
+type LookupOptions struct {
+	// PrefetchCount is the maximum number of descriptors adjacent to the one
+	// containing the looked-up key that the range lookup fetches and inserts
+	// into the cache along with it. Scans that are about to visit many
+	// consecutive ranges benefit from a large window; point lookups only need a
+	// small one.
+	//
+	// Lookups that get coalesced onto an in-flight lookup share its results,
+	// and thus its prefetch window.
+	PrefetchCount int64
+}
+
+// DefaultLookupOptions returns the LookupOptions to use when the caller has no
+// particular requirements.
+func DefaultLookupOptions() LookupOptions {
+	return LookupOptions{PrefetchCount: DefaultPrefetchCount}
+}
+
+// ScanPrefetch tracks the range lookup prefetch window reached by a caller
+// scanning sequentially across ranges. A caller that issues a scan as a series
+// of batches, like a paginated scan, attaches one to its context with
+// WithScanPrefetch. The lookups serving each batch then start from the window
+// reached by the previous ones instead of starting over. Callers that don't
+// attach one, such as unrelated requests running concurrently, are unaffected.
+//
+// ScanPrefetch is safe for concurrent use.
+type ScanPrefetch struct {
+	// window is accessed atomically.
+	window int64
+}
+
+// Window returns the prefetch window reached so far, or 0 if none was
+// recorded.
+func (sp *ScanPrefetch) Window() int64 {
+	return atomic.LoadInt64(&sp.window)
+}
+
+// Record records that a scan reached the given prefetch window. The recorded
+// window never shrinks.
+func (sp *ScanPrefetch) Record(window int64) {
+	for {
+		cur := atomic.LoadInt64(&sp.window)
+		if window <= cur || atomic.CompareAndSwapInt64(&sp.window, cur, window) {
+			return
+		}
+	}
+}
+
+type scanPrefetchKey struct{}
+
+// WithScanPrefetch returns a context carrying a new ScanPrefetch, unless ctx
+// already carries one.
+func WithScanPrefetch(ctx context.Context) context.Context {
+	if ScanPrefetchFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, scanPrefetchKey{}, &ScanPrefetch{})
+}
+
+// ScanPrefetchFromContext returns the ScanPrefetch carried by ctx, if any.
+func ScanPrefetchFromContext(ctx context.Context) *ScanPrefetch {
+	sp, _ := ctx.Value(scanPrefetchKey{}).(*ScanPrefetch)
+	return sp
+}
+
 // RangeCache is used to retrieve range descriptors for
 // arbitrary keys. Descriptors are initially queried from storage
 // using a RangeDescriptorDB, but are cached for subsequent lookups.
 type RangeCache struct {
-	st      *cluster.Settings
-	stopper *stop.Stopper
-	tracer  *tracing.Tracer
+	st         *cluster.Settings
+	stopper    *stop.Stopper
+	tracer     *tracing.Tracer
+	timeSource timeutil.TimeSource
+	metrics    Metrics
+	// maxAge, if positive, is the duration (in nanoseconds) after which cached
+	// entries are considered expired by lookups. Accessed atomically.
+	maxAge int64
+	// maxBytes, if set and positive, bounds the estimated memory footprint of
+	// the cache's entries, in addition to the bound on their count.
+	maxBytes func() int64
 	// RangeDescriptorDB is used to retrieve range descriptors from the
 	// database, which will be cached by this structure.
 	db RangeDescriptorDB
@@ -87,6 +171,12 @@ type RangeCache struct {
 	rangeCache struct {
 		syncutil.RWMutex
 		cache *cache.OrderedCache
+		// bytes is the estimated memory footprint of the cache's entries.
+		bytes int64
+		// byRangeID indexes the cache's entries by range ID.
+		byRangeID map[roachpb.RangeID]rangeCacheKey
+		// onEvict are the callbacks registered through OnEvict.
+		onEvict []func(desc *roachpb.RangeDescriptor)
 	}
 	// lookupRequests stores all inflight requests retrieving range
 	// descriptors from the database. It allows multiple RangeDescriptorDB
@@ -99,6 +189,97 @@ type RangeCache struct {
 	// another in-flight one. Used by tests to block until a lookup request is
 	// blocked on the single-flight querying the db.
 	coalesced chan struct{}
+
+	// refreshSem limits the number of concurrent background refreshes started
+	// by RefreshAsync.
+	refreshSem *quotapool.IntPool
+	// refreshing holds the IDs of the ranges for which a background refresh is
+	// in flight, so that a range that keeps returning errors doesn't pile up
+	// refreshes.
+	refreshing struct {
+		syncutil.Mutex
+		m map[roachpb.RangeID]struct{}
+	}
+
+	// emptyLookups remembers the range lookups that recently returned no
+	// descriptors.
+	emptyLookups emptyLookups
+}
+
+// refreshTaskLimit is the maximum number of concurrent background descriptor
+// refreshes.
+const refreshTaskLimit = 16
+
+const (
+	// emptyLookupInitialBackoff is how long a range lookup that returned no
+	// descriptors is remembered for the first time.
+	emptyLookupInitialBackoff = 10 * time.Millisecond
+	// emptyLookupMaxBackoff caps the backoff for range lookups that keep
+	// returning no descriptors.
+	emptyLookupMaxBackoff = time.Second
+)
+
+// emptyLookups is a short-lived negative cache for range lookups that returned
+// no descriptors, which can happen transiently (for example when the lookup's
+// meta scan races with a split). While an entry is live, lookups with the same
+// request key (see makeLookupRequestKey) fail fast with the recorded error
+// instead of issuing another meta lookup. Consecutive empty results for the
+// same request key back off exponentially.
+type emptyLookups struct {
+	syncutil.Mutex
+	m map[string]*emptyLookup
+}
+
+type emptyLookup struct {
+	err error
+	// backoff is the duration for which err is returned. It doubles with every
+	// consecutive empty result.
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// get returns the error recorded for the given request key, if it hasn't
+// expired yet.
+func (el *emptyLookups) get(key string, now time.Time) error {
+	el.Lock()
+	defer el.Unlock()
+	if e, ok := el.m[key]; ok && now.Before(e.retryAt) {
+		return e.err
+	}
+	return nil
+}
+
+// record remembers that the lookup for the given request key returned no
+// descriptors.
+func (el *emptyLookups) record(key string, err error, now time.Time) {
+	el.Lock()
+	defer el.Unlock()
+	// Forget about lookups that haven't been retried in a while, so that the map
+	// doesn't accumulate request keys that are never looked up again.
+	for k, e := range el.m {
+		if now.Sub(e.retryAt) > emptyLookupMaxBackoff {
+			delete(el.m, k)
+		}
+	}
+	e, ok := el.m[key]
+	if !ok {
+		e = &emptyLookup{backoff: emptyLookupInitialBackoff}
+		el.m[key] = e
+	} else {
+		e.backoff *= 2
+		if e.backoff > emptyLookupMaxBackoff {
+			e.backoff = emptyLookupMaxBackoff
+		}
+	}
+	e.err = err
+	e.retryAt = now.Add(e.backoff)
+}
+
+// clear forgets about any empty result recorded for the given request key.
+func (el *emptyLookups) clear(key string) {
+	el.Lock()
+	defer el.Unlock()
+	delete(el.m, key)
 }
 
 // makeLookupRequestKey constructs a key for the lookupRequest group with the
@@ -182,16 +363,109 @@ func NewRangeCache(
 	stopper *stop.Stopper,
 	tracer *tracing.Tracer,
 ) *RangeCache {
-	rdc := &RangeCache{st: st, db: db, stopper: stopper, tracer: tracer}
+	rdc := &RangeCache{
+		st:         st,
+		db:         db,
+		stopper:    stopper,
+		tracer:     tracer,
+		timeSource: timeutil.DefaultTimeSource{},
+		metrics:    makeMetrics(),
+	}
 	rdc.rangeCache.cache = cache.NewOrderedCache(cache.Config{
 		Policy: cache.CacheLRU,
-		ShouldEvict: func(n int, _, _ interface{}) bool {
-			return int64(n) > size()
+		ShouldEvict: func(n int, _, v interface{}) bool {
+			if int64(n) > size() || rdc.overBytesLimitLocked() {
+				rdc.metrics.Evictions.Inc(1)
+				rdc.notifyEvictLocked(v.(*CacheEntry).Desc())
+				return true
+			}
+			return false
+		},
+		OnEvictedEntry: func(e *cache.Entry) {
+			rdc.onRemovedLocked(e.Key.(rangeCacheKey), e.Value.(*CacheEntry))
 		},
 	})
+	rdc.refreshSem = quotapool.NewIntPool("range cache refresh", refreshTaskLimit)
+	stopper.AddCloser(rdc.refreshSem.Closer("stopper"))
+	rdc.refreshing.m = make(map[roachpb.RangeID]struct{})
+	rdc.emptyLookups.m = make(map[string]*emptyLookup)
+	rdc.rangeCache.byRangeID = make(map[roachpb.RangeID]rangeCacheKey)
 	return rdc
 }
 
+// SetMaxBytes configures the cache to evict entries, in LRU order, whenever the
+// estimated memory footprint of its entries exceeds maxBytes(). This is useful
+// because the size of descriptors varies widely with their number of replicas,
+// so bounding the number of entries alone doesn't bound the cache's memory
+// use. A nil maxBytes, or one returning a non-positive value, disables the
+// limit. Must be called before the cache is used.
+func (rc *RangeCache) SetMaxBytes(maxBytes func() int64) {
+	rc.maxBytes = maxBytes
+}
+
+// overBytesLimitLocked returns whether the cache's entries exceed the limit
+// configured through SetMaxBytes.
+func (rc *RangeCache) overBytesLimitLocked() bool {
+	if rc.maxBytes == nil {
+		return false
+	}
+	maxBytes := rc.maxBytes()
+	return maxBytes > 0 && rc.rangeCache.bytes > maxBytes
+}
+
+// entrySize estimates the memory footprint of a cache entry, based on the
+// marshaled size of its descriptor and lease.
+func entrySize(key rangeCacheKey, e *CacheEntry) int64 {
+	return int64(len(key) + e.desc.Size() + e.lease.Size())
+}
+
+// addLocked adds an entry to the cache, keeping track of its size and
+// indexing it by range ID.
+func (rc *RangeCache) addLocked(key rangeCacheKey, e *CacheEntry) {
+	if old, ok := rc.rangeCache.cache.StealthyGet(key); ok {
+		// The old entry gets overwritten without going through OnEvictedEntry.
+		rc.onRemovedLocked(key, old.(*CacheEntry))
+	}
+	rc.rangeCache.bytes += entrySize(key, e)
+	if e.hits == nil {
+		e.hits = new(int64)
+	}
+	if e.desc.RangeID != 0 {
+		rc.rangeCache.byRangeID[e.desc.RangeID] = key
+	}
+	rc.rangeCache.cache.Add(key, e)
+}
+
+// onRemovedLocked undoes the bookkeeping done by addLocked when an entry is
+// removed from the cache.
+func (rc *RangeCache) onRemovedLocked(key rangeCacheKey, e *CacheEntry) {
+	rc.rangeCache.bytes -= entrySize(key, e)
+	if k, ok := rc.rangeCache.byRangeID[e.desc.RangeID]; ok && bytes.Equal(k, key) {
+		delete(rc.rangeCache.byRangeID, e.desc.RangeID)
+	}
+}
+
+// Metrics returns a struct which contains metrics related to the cache's
+// activity.
+func (rc *RangeCache) Metrics() Metrics {
+	return rc.metrics
+}
+
+// SetMaxAge configures the cache to treat entries that were inserted (or last
+// confirmed by a range lookup) more than maxAge ago as misses, which forces
+// lookups to re-fetch them. This bounds how long a descriptor can remain in use
+// on a read-mostly workload that never runs into errors caused by it being
+// stale. A zero maxAge, the default, disables expiration.
+func (rc *RangeCache) SetMaxAge(maxAge time.Duration) {
+	atomic.StoreInt64(&rc.maxAge, int64(maxAge))
+}
+
+// expired returns whether the entry is older than the cache's max age.
+func (rc *RangeCache) expired(e *CacheEntry) bool {
+	maxAge := time.Duration(atomic.LoadInt64(&rc.maxAge))
+	return maxAge > 0 && rc.timeSource.Since(e.cachedAt) > maxAge
+}
+
 func (rc *RangeCache) String() string {
 	rc.rangeCache.RLock()
 	defer rc.rangeCache.RUnlock()
@@ -519,11 +793,16 @@ func (et *EvictionToken) EvictAndReplace(ctx context.Context, newDescs ...roachp
 //
 // The returned EvictionToken contains the descriptor and, possibly, the lease.
 // It can also be used to evict information from the cache if it's found to be
-// stale.
+// stale. If the lookup misses the cache, opts control the range lookup that is
+// performed.
 func (rc *RangeCache) LookupWithEvictionToken(
-	ctx context.Context, key roachpb.RKey, evictToken EvictionToken, useReverseScan bool,
+	ctx context.Context,
+	key roachpb.RKey,
+	evictToken EvictionToken,
+	useReverseScan bool,
+	opts LookupOptions,
 ) (EvictionToken, error) {
-	tok, err := rc.lookupInternal(ctx, key, evictToken, useReverseScan)
+	tok, err := rc.lookupInternal(ctx, key, evictToken, useReverseScan, opts)
 	if err != nil {
 		return EvictionToken{}, err
 	}
@@ -535,7 +814,7 @@ func (rc *RangeCache) LookupWithEvictionToken(
 // LookupWithEvictionToken.
 func (rc *RangeCache) Lookup(ctx context.Context, key roachpb.RKey) (CacheEntry, error) {
 	tok, err := rc.lookupInternal(
-		ctx, key, EvictionToken{}, false /* useReverseScan */)
+		ctx, key, EvictionToken{}, false /* useReverseScan */, DefaultLookupOptions())
 	if err != nil {
 		return CacheEntry{}, err
 	}
@@ -593,11 +872,15 @@ func (rc *RangeCache) getCachedOverlappingRLocked(
 // added to the inflight request map (with or without merging) or the
 // function finishes. Used for testing.
 func (rc *RangeCache) lookupInternal(
-	ctx context.Context, key roachpb.RKey, evictToken EvictionToken, useReverseScan bool,
+	ctx context.Context,
+	key roachpb.RKey,
+	evictToken EvictionToken,
+	useReverseScan bool,
+	opts LookupOptions,
 ) (EvictionToken, error) {
 	// Retry while we're hitting lookupCoalescingErrors.
 	for {
-		newToken, err := rc.tryLookup(ctx, key, evictToken, useReverseScan)
+		newToken, err := rc.tryLookup(ctx, key, evictToken, useReverseScan, opts)
 		if errors.HasType(err, (lookupCoalescingError{})) {
 			log.VEventf(ctx, 2, "bad lookup coalescing; retrying: %s", err)
 			continue
@@ -634,22 +917,40 @@ func newLookupCoalescingError(key roachpb.RKey, wrongDesc *roachpb.RangeDescript
 
 // tryLookup can return a lookupCoalescingError.
 func (rc *RangeCache) tryLookup(
-	ctx context.Context, key roachpb.RKey, evictToken EvictionToken, useReverseScan bool,
+	ctx context.Context,
+	key roachpb.RKey,
+	evictToken EvictionToken,
+	useReverseScan bool,
+	opts LookupOptions,
 ) (EvictionToken, error) {
 	rc.rangeCache.RLock()
+	var prevDesc *roachpb.RangeDescriptor
 	if entry, _ := rc.getCachedRLocked(ctx, key, useReverseScan); entry != nil {
-		rc.rangeCache.RUnlock()
-		returnToken := rc.makeEvictionToken(entry, nil /* nextDesc */)
-		return returnToken, nil
+		if !rc.expired(entry) {
+			entry.recordHit()
+			rc.rangeCache.RUnlock()
+			rc.metrics.CacheHits.Inc(1)
+			returnToken := rc.makeEvictionToken(entry, nil /* nextDesc */)
+			return returnToken, nil
+		}
+		// The entry is too old to be trusted; treat it as a miss. The expired
+		// descriptor is used to coalesce the lookups for keys in its span.
+		log.VEventf(ctx, 2, "cached range descriptor expired: %s", entry)
+		prevDesc = entry.Desc()
 	}
 
+	rc.metrics.CacheMisses.Inc(1)
 	log.VEventf(ctx, 2, "looking up range descriptor: key=%s", key)
 
-	var prevDesc *roachpb.RangeDescriptor
 	if evictToken.Valid() {
 		prevDesc = evictToken.Desc()
 	}
 	requestKey := makeLookupRequestKey(key, prevDesc, useReverseScan)
+	if err := rc.emptyLookups.get(requestKey, rc.timeSource.Now()); err != nil {
+		rc.rangeCache.RUnlock()
+		log.VEventf(ctx, 2, "range lookup recently returned no descriptors; not retrying yet: %s", err)
+		return EvictionToken{}, err
+	}
 	// Fork a context with a new span before reqCtx is captured by the DoChan
 	// closure below; the parent span might get finished by the time the closure
 	// starts. In the "leader" case, the closure will take ownership of the new
@@ -673,7 +974,7 @@ func (rc *RangeCache) tryLookup(
 			if err := contextutil.RunWithTimeout(ctx, "range lookup", 10*time.Second,
 				func(ctx context.Context) error {
 					var err error
-					rs, preRs, err = rc.performRangeLookup(ctx, key, useReverseScan)
+					rs, preRs, err = rc.performRangeLookup(ctx, key, useReverseScan, opts)
 					return err
 				}); err != nil {
 				return err
@@ -681,10 +982,13 @@ func (rc *RangeCache) tryLookup(
 
 			switch {
 			case len(rs) == 0:
-				return fmt.Errorf("no range descriptors returned for %s", key)
+				err := fmt.Errorf("no range descriptors returned for %s", key)
+				rc.emptyLookups.record(requestKey, err, rc.timeSource.Now())
+				return err
 			case len(rs) > 2:
 				panic(fmt.Sprintf("more than 2 matching range descriptors returned for %s: %v", key, rs))
 			}
+			rc.emptyLookups.clear(requestKey)
 
 			// We want to be assured that all goroutines which experienced a cache miss
 			// have joined our in-flight request, and all others will experience a
@@ -709,7 +1013,17 @@ func (rc *RangeCache) tryLookup(
 			for i, preR := range preRs {
 				newEntries[i+1] = &CacheEntry{desc: preR}
 			}
-			insertedEntries := rc.insertLockedInner(ctx, newEntries)
+			insertedEntries := rc.insertLookupResultsLocked(ctx, newEntries)
+			// Only count the prefetched descriptors that were actually added,
+			// i.e. not the ones that the cache already had or that were
+			// superseded by newer cached information.
+			var prefetchAdds int64
+			for i := 1; i < len(newEntries); i++ {
+				if insertedEntries[i] == newEntries[i] {
+					prefetchAdds++
+				}
+			}
+			rc.metrics.PrefetchAdds.Inc(prefetchAdds)
 			// entry corresponds to rs[0], which is the descriptor covering the key
 			// we're interested in.
 			entry := insertedEntries[0]
@@ -809,15 +1123,27 @@ func (rc *RangeCache) tryLookup(
 // performRangeLookup handles delegating the range lookup to the cache's
 // RangeDescriptorDB.
 func (rc *RangeCache) performRangeLookup(
-	ctx context.Context, key roachpb.RKey, useReverseScan bool,
+	ctx context.Context, key roachpb.RKey, useReverseScan bool, opts LookupOptions,
 ) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error) {
 	// Tag inner operations.
 	ctx = logtags.AddTag(ctx, "range-lookup", key)
+	start := timeutil.Now()
+	defer func() {
+		rc.metrics.LookupLatency.RecordValue(timeutil.Since(start).Nanoseconds())
+	}()
+
+	level := lookupLevel(key)
+	if sp := tracing.SpanFromContext(ctx); sp != nil {
+		sp.SetTag("key", attribute.StringValue(key.String()))
+		sp.SetTag("level", attribute.StringValue(level))
+		sp.SetTag("prefetch", attribute.Int64Value(opts.PrefetchCount))
+		sp.SetTag("reverse", attribute.BoolValue(useReverseScan))
+	}
 
 	// In this case, the requested key is stored in the cluster's first
 	// range. Return the first range, which is always gossiped and not
 	// queried from the datastore.
-	if keys.RangeMetaKey(key).Equal(roachpb.RKeyMin) {
+	if level == lookupLevelFirstRange {
 		desc, err := rc.db.FirstRange()
 		if err != nil {
 			return nil, nil, err
@@ -825,16 +1151,163 @@ func (rc *RangeCache) performRangeLookup(
 		return []roachpb.RangeDescriptor{*desc}, nil, nil
 	}
 
-	return rc.db.RangeLookup(ctx, key, useReverseScan)
+	return rc.db.RangeLookup(ctx, key, opts.PrefetchCount, useReverseScan)
+}
+
+const (
+	lookupLevelFirstRange = "first-range"
+	lookupLevelMeta1      = "meta1"
+	lookupLevelMeta2      = "meta2"
+)
+
+// lookupLevel returns the level of the range addressing hierarchy that is
+// consulted to look up the descriptor of the range containing key.
+func lookupLevel(key roachpb.RKey) string {
+	metaKey := keys.RangeMetaKey(key)
+	switch {
+	case metaKey.Equal(roachpb.RKeyMin):
+		return lookupLevelFirstRange
+	case bytes.HasPrefix(metaKey, keys.Meta1Prefix):
+		return lookupLevelMeta1
+	default:
+		return lookupLevelMeta2
+	}
+}
+
+// RefreshAsync re-validates the cached descriptor for the given range in the
+// background. It is meant to be called when a request using desc got an error
+// hinting that desc may be stale but not carrying a newer descriptor (for
+// example, a RangeNotFoundError from one of its replicas); errors that do
+// carry one should use UpdateDescriptor instead. Unlike evicting the entry,
+// which makes the next request for the range pay for a range lookup, the stale
+// entry stays in the cache until the refreshed descriptor replaces it.
+//
+// At most one refresh per range is in flight at a time, and refreshes are
+// dropped when too many are already running.
+func (rc *RangeCache) RefreshAsync(ctx context.Context, desc *roachpb.RangeDescriptor) {
+	rangeID, key := desc.RangeID, desc.StartKey
+	rc.refreshing.Lock()
+	if _, ok := rc.refreshing.m[rangeID]; ok {
+		rc.refreshing.Unlock()
+		return
+	}
+	rc.refreshing.m[rangeID] = struct{}{}
+	rc.refreshing.Unlock()
+	done := func() {
+		rc.refreshing.Lock()
+		defer rc.refreshing.Unlock()
+		delete(rc.refreshing.m, rangeID)
+	}
+
+	// The refresh outlives the request that triggered it, so it shouldn't
+	// inherit its cancelation. The task's span still follows from the
+	// request's, so that the refresh shows up in the request's trace.
+	ctx = tracing.ContextWithSpan(
+		logtags.WithTags(context.Background(), logtags.FromContext(ctx)),
+		tracing.SpanFromContext(ctx))
+	if err := rc.stopper.RunAsyncTaskEx(ctx,
+		stop.TaskOpts{
+			TaskName:   "rangecache: refresh",
+			SpanOpt:    stop.FollowsFromSpan,
+			Sem:        rc.refreshSem,
+			WaitForSem: false,
+		},
+		func(ctx context.Context) {
+			defer done()
+			if err := rc.refresh(ctx, key); err != nil {
+				log.VEventf(ctx, 2, "background refresh of r%d failed: %s", rangeID, err)
+			}
+		}); err != nil {
+		done()
+		log.VEventf(ctx, 2, "not refreshing r%d: %s", rangeID, err)
+	}
+}
+
+// refresh performs a range lookup for the range containing key and inserts the
+// result into the cache, replacing the cached entry if the result is newer.
+// Contrary to a regular lookup, the cache isn't consulted first.
+func (rc *RangeCache) refresh(ctx context.Context, key roachpb.RKey) error {
+	var rs []roachpb.RangeDescriptor
+	if err := contextutil.RunWithTimeout(ctx, "range refresh", 10*time.Second,
+		func(ctx context.Context) error {
+			var err error
+			rs, _, err = rc.performRangeLookup(
+				ctx, key, false /* useReverseScan */, LookupOptions{PrefetchCount: 0})
+			return err
+		}); err != nil {
+		return err
+	}
+	if len(rs) == 0 {
+		return errors.Errorf("no range descriptors returned for %s", key)
+	}
+
+	rc.rangeCache.Lock()
+	defer rc.rangeCache.Unlock()
+	// As in tryLookup, we don't insert rs[1] (if any) since it overlaps rs[0].
+	rc.insertLookupResultsLocked(ctx, []*CacheEntry{{
+		desc: rs[0],
+		// We don't have any lease information.
+		lease: roachpb.Lease{},
+		// We don't know the closed timestamp policy.
+		closedts: roachpb.LAG_BY_CLUSTER_SETTING,
+	}})
+	return nil
+}
+
+// UpdateDescriptor updates the cache with a descriptor learned out of band,
+// for example through gossip, as opposed to through a request that ran into a
+// stale cache entry. Cached entries overlapping desc that are older than it are
+// evicted and desc is inserted in their place. If desc is identical to the
+// cached descriptor, the cached entry (and the lease information it carries) is
+// left in place. Entries newer than desc are not affected.
+func (rc *RangeCache) UpdateDescriptor(ctx context.Context, desc *roachpb.RangeDescriptor) {
+	rc.rangeCache.Lock()
+	defer rc.rangeCache.Unlock()
+	rc.insertLookupResultsLocked(ctx, []*CacheEntry{{
+		desc: *desc,
+		// We don't have any lease information.
+		lease: roachpb.Lease{},
+		// We don't know the closed timestamp policy.
+		closedts: roachpb.LAG_BY_CLUSTER_SETTING,
+	}})
 }
 
 // Clear clears all RangeDescriptors from the RangeCache.
 func (rc *RangeCache) Clear() {
 	rc.rangeCache.Lock()
 	defer rc.rangeCache.Unlock()
+	if len(rc.rangeCache.onEvict) > 0 {
+		rc.rangeCache.cache.Do(func(_, v interface{}) bool {
+			rc.notifyEvictLocked(v.(*CacheEntry).Desc())
+			return false
+		})
+	}
 	rc.rangeCache.cache.Clear()
 }
 
+// OnEvict registers a callback to be invoked with the descriptor of every entry
+// evicted from the cache, whether because it was found to be stale, because it
+// was replaced by an entry with a different descriptor, to make room for other
+// entries, or because the cache was cleared. Entries replaced by ones with the
+// same descriptor (e.g. to update their lease) don't count as evictions. This
+// allows structures that track per-range state to be cleaned up in lockstep
+// with the cache.
+//
+// The callbacks are invoked while holding the cache's lock; they must not block
+// nor call back into the cache. The descriptor must not be modified.
+func (rc *RangeCache) OnEvict(fn func(desc *roachpb.RangeDescriptor)) {
+	rc.rangeCache.Lock()
+	defer rc.rangeCache.Unlock()
+	rc.rangeCache.onEvict = append(rc.rangeCache.onEvict, fn)
+}
+
+// notifyEvictLocked invokes the OnEvict callbacks for an evicted descriptor.
+func (rc *RangeCache) notifyEvictLocked(desc *roachpb.RangeDescriptor) {
+	for _, fn := range rc.rangeCache.onEvict {
+		fn(desc)
+	}
+}
+
 // EvictByKey evicts the descriptor containing the given key, if any.
 //
 // Returns true if a descriptor was evicted.
@@ -848,6 +1321,35 @@ func (rc *RangeCache) EvictByKey(ctx context.Context, descKey roachpb.RKey) bool
 	}
 	log.VEventf(ctx, 2, "evict cached descriptor: %s", cachedDesc)
 	rc.rangeCache.cache.DelEntry(entry)
+	rc.metrics.Evictions.Inc(1)
+	rc.notifyEvictLocked(cachedDesc.Desc())
+	return true
+}
+
+// EvictByRangeID evicts the descriptor of the given range, if any. It is meant
+// for callers that know which range is stale (for example because of a
+// RangeNotFoundError) but not necessarily its key span.
+//
+// Returns true if a descriptor was evicted.
+func (rc *RangeCache) EvictByRangeID(ctx context.Context, rangeID roachpb.RangeID) bool {
+	rc.rangeCache.Lock()
+	defer rc.rangeCache.Unlock()
+
+	key, ok := rc.rangeCache.byRangeID[rangeID]
+	if !ok {
+		return false
+	}
+	cached, ok := rc.rangeCache.cache.StealthyGet(key)
+	if !ok || cached.(*CacheEntry).desc.RangeID != rangeID {
+		log.Errorf(ctx, "%s", errors.AssertionFailedf(
+			"range cache index out of sync: r%d indexed at %s, found %v", rangeID, key, cached).Error())
+		delete(rc.rangeCache.byRangeID, rangeID)
+		return false
+	}
+	log.VEventf(ctx, 2, "evict cached descriptor: %s", cached)
+	rc.rangeCache.cache.Del(key)
+	rc.metrics.Evictions.Inc(1)
+	rc.notifyEvictLocked(cached.(*CacheEntry).Desc())
 	return true
 }
 
@@ -869,6 +1371,8 @@ func (rc *RangeCache) evictDescLocked(ctx context.Context, desc *roachpb.RangeDe
 	// and the cache is not expected to go backwards). Evict it.
 	log.VEventf(ctx, 2, "evict cached descriptor: desc=%s", cachedEntry)
 	rc.rangeCache.cache.DelEntry(rawEntry)
+	rc.metrics.Evictions.Inc(1)
+	rc.notifyEvictLocked(cachedDesc)
 	return true
 }
 
@@ -1002,12 +1506,53 @@ func (rc *RangeCache) insertLockedInner(ctx context.Context, rs []*CacheEntry) [
 		if log.V(2) {
 			log.Infof(ctx, "adding cache entry: value=%s", ent)
 		}
-		rc.rangeCache.cache.Add(rangeCacheKey(rangeKey), ent)
+		ent.cachedAt = rc.timeSource.Now()
+		rc.addLocked(rangeCacheKey(rangeKey), ent)
 		entries[i] = ent
 	}
 	return entries
 }
 
+// insertLookupResultsLocked is like insertLockedInner, but it is used for
+// entries coming from a range lookup. Such entries carry neither lease nor
+// closed timestamp policy information, so an entry whose descriptor is
+// identical to the one already cached for the same span has nothing to add.
+// These entries are diffed out and the cached entries are left in place;
+// evicting and re-adding them would discard the cached lease information and
+// disturb the LRU position of entries that are still valid. The only thing
+// updated for them is the time at which they were last confirmed, which resets
+// their expiration (see SetMaxAge). Only the entries whose descriptors actually
+// changed are inserted.
+//
+// Like insertLockedInner, the returned slice is parallel to rs.
+func (rc *RangeCache) insertLookupResultsLocked(
+	ctx context.Context, rs []*CacheEntry,
+) []*CacheEntry {
+	entries := make([]*CacheEntry, len(rs))
+	changed := make([]*CacheEntry, 0, len(rs))
+	changedIdx := make([]int, 0, len(rs))
+	for i, ent := range rs {
+		cached, rawEntry := rc.getCachedRLocked(ctx, ent.desc.StartKey, false /* inverted */)
+		if cached != nil && cached.desc.Equal(&ent.desc) {
+			log.VEventf(ctx, 2, "range lookup returned unchanged descriptor: %s", cached.Desc())
+			// CacheEntries are immutable, so replace the cached one with a copy.
+			// Setting the value directly, as opposed to going through
+			// swapEntryLocked(), doesn't affect the entry's LRU position.
+			confirmed := *cached
+			confirmed.cachedAt = rc.timeSource.Now()
+			rawEntry.Value = &confirmed
+			entries[i] = &confirmed
+			continue
+		}
+		changed = append(changed, ent)
+		changedIdx = append(changedIdx, i)
+	}
+	for i, ent := range rc.insertLockedInner(ctx, changed) {
+		entries[changedIdx[i]] = ent
+	}
+	return entries
+}
+
 func (rc *RangeCache) getValue(entry *cache.Entry) *CacheEntry {
 	return entry.Value.(*CacheEntry)
 }
@@ -1044,6 +1589,12 @@ func (rc *RangeCache) clearOlderOverlappingLocked(
 				log.Infof(ctx, "clearing overlapping descriptor: key=%s entry=%s", e.Key, rc.getValue(e))
 			}
 			rc.rangeCache.cache.DelEntry(e)
+			rc.metrics.Evictions.Inc(1)
+			// If the descriptor didn't change (i.e. only the lease did), it's not
+			// really being evicted.
+			if !entry.desc.Equal(newEntry.Desc()) {
+				rc.notifyEvictLocked(entry.Desc())
+			}
 		} else {
 			newest = false
 			if descsCompatible(entry.Desc(), newEntry.Desc()) {
@@ -1075,10 +1626,13 @@ func (rc *RangeCache) swapEntryLocked(
 	}
 
 	rc.rangeCache.cache.DelEntry(oldEntry)
-	if newEntry != nil {
-		log.VEventf(ctx, 2, "caching new entry: %s", newEntry)
-		rc.rangeCache.cache.Add(oldEntry.Key, newEntry)
+	if newEntry == nil {
+		rc.metrics.Evictions.Inc(1)
+		rc.notifyEvictLocked(rc.getValue(oldEntry).Desc())
+		return
 	}
+	log.VEventf(ctx, 2, "caching new entry: %s", newEntry)
+	rc.addLocked(oldEntry.Key.(rangeCacheKey), newEntry)
 }
 
 // DB returns the descriptor database, for tests.
@@ -1113,12 +1667,35 @@ type CacheEntry struct {
 	lease roachpb.Lease
 	// closedts indicates the range's closed timestamp policy.
 	closedts roachpb.RangeClosedTimestampPolicy
+	// cachedAt is the time at which desc was inserted into the cache, or last
+	// confirmed by a range lookup. Entries derived from this one through lease
+	// updates inherit it. See RangeCache.SetMaxAge.
+	cachedAt time.Time
+	// hits counts the lookups served by this entry. It is shared with the
+	// entries derived from this one through lease updates, and is only ever
+	// accessed atomically. Assigned when the entry is added to the cache.
+	hits *int64
 }
 
 func (e CacheEntry) String() string {
 	return fmt.Sprintf("desc:%s, lease:%s", e.Desc(), e.lease)
 }
 
+// recordHit increments the entry's hit count.
+func (e *CacheEntry) recordHit() {
+	if e.hits != nil {
+		atomic.AddInt64(e.hits, 1)
+	}
+}
+
+// numHits returns the number of lookups served by the entry.
+func (e *CacheEntry) numHits() int64 {
+	if e.hits == nil {
+		return 0
+	}
+	return atomic.LoadInt64(e.hits)
+}
+
 // Desc returns the cached descriptor. Note that, besides being possibly stale,
 // this descriptor also might not represent a descriptor that was ever
 // committed. See DescSpeculative().
@@ -1360,6 +1937,8 @@ func (e *CacheEntry) updateLease(
 		desc:     e.desc,
 		lease:    *l,
 		closedts: e.closedts,
+		cachedAt: e.cachedAt,
+		hits:     e.hits,
 	}
 }
 
@@ -1372,6 +1951,8 @@ func (e *CacheEntry) evictLeaseholder(
 	return true, &CacheEntry{
 		desc:     e.desc,
 		closedts: e.closedts,
+		cachedAt: e.cachedAt,
+		hits:     e.hits,
 	}
 }
 
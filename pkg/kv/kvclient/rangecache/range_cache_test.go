@@ -14,6 +14,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"html"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -24,9 +25,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/assert"
@@ -65,14 +68,19 @@ func (db *testDescriptorDB) notifyOn(key roachpb.RKey) <-chan struct{} {
 	return ch
 }
 
+// testLookupOptions are the LookupOptions used by the tests. Many tests assert
+// on which descriptors get prefetched, assuming a window of two.
+var testLookupOptions = LookupOptions{PrefetchCount: 2}
+
 // getDescriptors scans the testDescriptorDB starting at the provided key in the
-// specified direction and collects the first RangeDescriptors that it finds.
+// specified direction and collects the RangeDescriptor containing the key plus
+// up to prefetchNum of the following RangeDescriptors that it finds.
 func (db *testDescriptorDB) getDescriptors(
-	key roachpb.RKey, useReverseScan bool,
+	key roachpb.RKey, prefetchNum int64, useReverseScan bool,
 ) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error) {
 	rs := make([]roachpb.RangeDescriptor, 0, 1)
-	preRs := make([]roachpb.RangeDescriptor, 0, 2)
-	for i := 0; i < 3; i++ {
+	preRs := make([]roachpb.RangeDescriptor, 0, prefetchNum)
+	for i := int64(0); i <= prefetchNum; i++ {
 		var endKey roachpb.RKey
 		if useReverseScan {
 			endKey = key
@@ -115,7 +123,7 @@ func (db *testDescriptorDB) getDescriptors(
 }
 
 func (db *testDescriptorDB) FirstRange() (*roachpb.RangeDescriptor, error) {
-	rs, _, err := db.getDescriptors(roachpb.RKeyMin, false /* useReverseScan */)
+	rs, _, err := db.getDescriptors(roachpb.RKeyMin, 0 /* prefetchNum */, false /* useReverseScan */)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +131,7 @@ func (db *testDescriptorDB) FirstRange() (*roachpb.RangeDescriptor, error) {
 }
 
 func (db *testDescriptorDB) RangeLookup(
-	ctx context.Context, key roachpb.RKey, useReverseScan bool,
+	ctx context.Context, key roachpb.RKey, prefetchNum int64, useReverseScan bool,
 ) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error) {
 	// Notify the test of the lookup, if the test wants notifications.
 	if ch, ok := db.listeners[key.String()]; ok {
@@ -136,7 +144,7 @@ func (db *testDescriptorDB) RangeLookup(
 	}
 
 	atomic.AddInt64(&db.lookupCount, 1)
-	rs, preRs, err := db.getDescriptors(key, useReverseScan)
+	rs, preRs, err := db.getDescriptors(key, prefetchNum, useReverseScan)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -160,7 +168,8 @@ func (db *testDescriptorDB) simulateLookupScan(
 ) error {
 	metaKey := keys.RangeMetaKey(key)
 	for {
-		tok, err := db.cache.LookupWithEvictionToken(ctx, metaKey, EvictionToken{}, useReverseScan)
+		tok, err := db.cache.LookupWithEvictionToken(
+			ctx, metaKey, EvictionToken{}, useReverseScan, testLookupOptions)
 		if err != nil {
 			return err
 		}
@@ -310,7 +319,7 @@ func doLookupWithToken(
 	// goroutines than the test's main one.
 
 	returnToken, err := rc.lookupInternal(
-		ctx, roachpb.RKey(key), evictToken, useReverseScan)
+		ctx, roachpb.RKey(key), evictToken, useReverseScan, testLookupOptions)
 	if err != nil {
 		panic(fmt.Sprintf("unexpected error from Lookup: %s", err))
 	}
@@ -351,7 +360,7 @@ func TestDescriptorDBGetDescriptors(t *testing.T) {
 	}
 
 	for useReverseScan, expectedRspans := range expectedRspansMap {
-		descs, preDescs, pErr := db.getDescriptors(key, useReverseScan)
+		descs, preDescs, pErr := db.getDescriptors(key, testLookupOptions.PrefetchCount, useReverseScan)
 		if pErr != nil {
 			t.Fatal(pErr)
 		}
@@ -502,6 +511,262 @@ func TestLookupByKeyMin(t *testing.T) {
 	require.True(t, entMin == entNext)
 }
 
+// TestRangeCachePrefetchCount verifies that the number of descriptors
+// prefetched by a lookup is controlled by LookupOptions.PrefetchCount.
+func TestRangeCachePrefetchCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	db := initTestDescriptorDB(t)
+	defer db.stop()
+
+	lookup := func(key string, prefetch int64) {
+		t.Helper()
+		_, err := db.cache.LookupWithEvictionToken(
+			ctx, roachpb.RKey(key), EvictionToken{}, false, /* useReverseScan */
+			LookupOptions{PrefetchCount: prefetch},
+		)
+		require.NoError(t, err)
+	}
+	cached := func(key string) bool {
+		return db.cache.GetCached(ctx, roachpb.RKey(key), false /* inverted */) != nil
+	}
+
+	// A point lookup doesn't prefetch anything.
+	lookup("d", 0)
+	require.True(t, cached("d"))
+	require.False(t, cached("e"))
+
+	// A larger window prefetches the following descriptors.
+	lookup("m", 5)
+	for _, k := range []string{"m", "n", "o", "p", "q", "r"} {
+		require.True(t, cached(k), k)
+	}
+	require.False(t, cached("s"))
+}
+
+// TestRangeCacheMetrics verifies that the cache keeps track of hits, misses,
+// prefetched entries and evictions.
+func TestRangeCacheMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	db := initTestDescriptorDB(t)
+	defer db.stop()
+	m := db.cache.Metrics()
+
+	// The first lookup misses, both for the range and for its meta2 range. Each
+	// miss results in a range lookup.
+	doLookup(ctx, db.cache, "d")
+	misses := m.CacheMisses.Count()
+	require.Greater(t, misses, int64(0))
+	require.Equal(t, misses, m.LookupLatency.TotalCount())
+
+	// The second one hits.
+	hits := m.CacheHits.Count()
+	doLookup(ctx, db.cache, "d")
+	require.Equal(t, hits+1, m.CacheHits.Count())
+	require.Equal(t, misses, m.CacheMisses.Count())
+
+	// Prefetched descriptors are counted.
+	prefetched := m.PrefetchAdds.Count()
+	_, err := db.cache.LookupWithEvictionToken(
+		ctx, roachpb.RKey("m"), EvictionToken{}, false, /* useReverseScan */
+		LookupOptions{PrefetchCount: 5},
+	)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, m.PrefetchAdds.Count()-prefetched, int64(5))
+
+	// Prefetched descriptors that the cache already has aren't counted.
+	require.True(t, db.cache.EvictByKey(ctx, roachpb.RKey("m")))
+	prefetched = m.PrefetchAdds.Count()
+	_, err = db.cache.LookupWithEvictionToken(
+		ctx, roachpb.RKey("m"), EvictionToken{}, false, /* useReverseScan */
+		LookupOptions{PrefetchCount: 5},
+	)
+	require.NoError(t, err)
+	require.Equal(t, prefetched, m.PrefetchAdds.Count())
+
+	// Evictions are counted.
+	evictions := m.Evictions.Count()
+	require.True(t, db.cache.EvictByKey(ctx, roachpb.RKey("d")))
+	require.Equal(t, evictions+1, m.Evictions.Count())
+}
+
+// emptyDescriptorDB is a RangeDescriptorDB whose range lookups don't find any
+// descriptor until told otherwise.
+type emptyDescriptorDB struct {
+	lookups int64
+	desc    atomic.Value // *roachpb.RangeDescriptor
+}
+
+func (db *emptyDescriptorDB) RangeLookup(
+	ctx context.Context, key roachpb.RKey, prefetchNum int64, useReverseScan bool,
+) ([]roachpb.RangeDescriptor, []roachpb.RangeDescriptor, error) {
+	atomic.AddInt64(&db.lookups, 1)
+	if desc, _ := db.desc.Load().(*roachpb.RangeDescriptor); desc != nil {
+		return []roachpb.RangeDescriptor{*desc}, nil, nil
+	}
+	return nil, nil, nil
+}
+
+func (db *emptyDescriptorDB) FirstRange() (*roachpb.RangeDescriptor, error) {
+	return nil, errors.New("not implemented")
+}
+
+// TestRangeCacheEmptyLookupBackoff verifies that range lookups that return no
+// descriptors are remembered for a while, with exponential backoff, instead of
+// being retried right away.
+func TestRangeCacheEmptyLookupBackoff(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	st := cluster.MakeTestingClusterSettings()
+	tr := tracing.NewTracer()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	db := &emptyDescriptorDB{}
+	cache := NewRangeCache(st, db, staticSize(2<<10), stopper, tr)
+	manual := timeutil.NewManualTime(timeutil.Unix(0, 123))
+	cache.timeSource = manual
+
+	lookup := func() error {
+		_, err := cache.Lookup(ctx, roachpb.RKey("a"))
+		return err
+	}
+	lookups := func() int64 {
+		return atomic.LoadInt64(&db.lookups)
+	}
+
+	require.Error(t, lookup())
+	require.Equal(t, int64(1), lookups())
+	// The empty result is returned without another lookup until the backoff
+	// expires.
+	require.Error(t, lookup())
+	require.Equal(t, int64(1), lookups())
+	manual.Advance(emptyLookupInitialBackoff)
+	require.Error(t, lookup())
+	require.Equal(t, int64(2), lookups())
+
+	// The second consecutive empty result is remembered for twice as long.
+	manual.Advance(emptyLookupInitialBackoff)
+	require.Error(t, lookup())
+	require.Equal(t, int64(2), lookups())
+	manual.Advance(emptyLookupInitialBackoff)
+	db.desc.Store(&roachpb.RangeDescriptor{
+		RangeID:  1,
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("b"),
+	})
+	require.NoError(t, lookup())
+	require.Equal(t, int64(3), lookups())
+	require.Empty(t, cache.emptyLookups.m)
+}
+
+func TestLookupLevel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		key roachpb.RKey
+		exp string
+	}{
+		{roachpb.RKey("a"), lookupLevelMeta2},
+		{roachpb.RKeyMax, lookupLevelMeta2},
+		{keys.RangeMetaKey(roachpb.RKey("a")), lookupLevelMeta1},
+		{keys.RangeMetaKey(keys.RangeMetaKey(roachpb.RKey("a"))), lookupLevelFirstRange},
+		{roachpb.RKeyMin, lookupLevelFirstRange},
+	} {
+		t.Run(tc.key.String(), func(t *testing.T) {
+			require.Equal(t, tc.exp, lookupLevel(tc.key))
+		})
+	}
+}
+
+// TestRangeCacheMaxAge verifies that lookups treat entries older than the
+// cache's max age as misses, and that re-fetching them resets their age.
+func TestRangeCacheMaxAge(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	db := initTestDescriptorDB(t)
+	defer db.stop()
+
+	manual := timeutil.NewManualTime(timeutil.Unix(0, 123))
+	db.cache.timeSource = manual
+	db.cache.SetMaxAge(time.Minute)
+
+	// Each miss looks up both the range and its meta2 range.
+	doLookup(ctx, db.cache, "d")
+	db.assertLookupCountEq(t, 2, "d")
+	doLookup(ctx, db.cache, "d")
+	db.assertLookupCountEq(t, 0, "d")
+
+	manual.Advance(30 * time.Second)
+	doLookup(ctx, db.cache, "d")
+	db.assertLookupCountEq(t, 0, "d")
+
+	// Once expired, the (unchanged) descriptor is re-fetched...
+	manual.Advance(time.Minute)
+	doLookup(ctx, db.cache, "d")
+	db.assertLookupCountEq(t, 2, "d")
+	// ... which makes it fresh again.
+	doLookup(ctx, db.cache, "d")
+	db.assertLookupCountEq(t, 0, "d")
+
+	// Expired entries are still returned by GetCached.
+	manual.Advance(2 * time.Minute)
+	require.NotNil(t, db.cache.GetCached(ctx, roachpb.RKey("d"), false /* inverted */))
+
+	// Disabling expiration turns the expired entries into hits again.
+	db.cache.SetMaxAge(0)
+	doLookup(ctx, db.cache, "d")
+	db.assertLookupCountEq(t, 0, "d")
+}
+
+// TestRangeCacheRefreshAsync verifies that RefreshAsync replaces a stale cached
+// descriptor in the background, without evicting it in the meantime.
+func TestRangeCacheRefreshAsync(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	db := initTestDescriptorDB(t)
+	defer db.stop()
+
+	rs, _, err := db.getDescriptors(roachpb.RKey("d"), 0 /* prefetchNum */, false /* useReverseScan */)
+	require.NoError(t, err)
+	cur := rs[0]
+	require.Greater(t, int64(cur.Generation), int64(1))
+	stale := cur
+	stale.Generation--
+
+	db.pauseRangeLookups()
+	db.cache.Insert(ctx, roachpb.RangeInfo{Desc: stale})
+	db.cache.RefreshAsync(ctx, &stale)
+	// A second refresh for the same range is a no-op while the first one is in
+	// flight.
+	db.cache.RefreshAsync(ctx, &stale)
+
+	// While the refresh is blocked, the stale descriptor is still served.
+	ent := db.cache.GetCached(ctx, roachpb.RKey("d"), false /* inverted */)
+	require.NotNil(t, ent)
+	require.Equal(t, stale, *ent.Desc())
+
+	db.resumeRangeLookups()
+	testutils.SucceedsSoon(t, func() error {
+		ent := db.cache.GetCached(ctx, roachpb.RKey("d"), false /* inverted */)
+		if ent == nil {
+			return errors.New("descriptor was evicted")
+		}
+		if ent.Desc().Generation != cur.Generation {
+			return errors.Newf("expected generation %d, got %s", cur.Generation, ent.Desc())
+		}
+		return nil
+	})
+	// A single refresh ran: one lookup for the range and one for its meta2 range.
+	db.assertLookupCountEq(t, 2, "d")
+}
+
 // TestRangeCacheCoalescedRequests verifies that concurrent lookups for
 // the same key will be coalesced onto the same database lookup.
 func TestRangeCacheCoalescedRequests(t *testing.T) {
@@ -583,7 +848,7 @@ func TestRangeCacheContextCancellation(t *testing.T) {
 			blocked = ch
 		}
 		go func() {
-			_, err := db.cache.lookupInternal(ctx, key, EvictionToken{}, false)
+			_, err := db.cache.lookupInternal(ctx, key, EvictionToken{}, false, testLookupOptions)
 			errC <- err
 		}()
 		<-blocked
@@ -677,7 +942,7 @@ func TestRangeCacheDetectSplit(t *testing.T) {
 	// such that a RangeKeyMismatchError is returned.
 	_, evictToken := doLookup(ctx, db.cache, "az")
 	// mismatchErrRange mocks out a RangeKeyMismatchError.Range response.
-	ranges, _, pErr := db.getDescriptors(roachpb.RKey("aa"), false)
+	ranges, _, pErr := db.getDescriptors(roachpb.RKey("aa"), testLookupOptions.PrefetchCount, false)
 	if pErr != nil {
 		t.Fatal(pErr)
 	}
@@ -716,7 +981,7 @@ func TestRangeCacheDetectSplitReverseScan(t *testing.T) {
 	useReverseScan := true
 	_, evictToken := doLookupWithToken(ctx, db.cache, "az", EvictionToken{}, useReverseScan)
 	// mismatchErrRange mocks out a RangeKeyMismatchError.Range response.
-	ranges, _, pErr := db.getDescriptors(roachpb.RKey("aa"), false)
+	ranges, _, pErr := db.getDescriptors(roachpb.RKey("aa"), testLookupOptions.PrefetchCount, false)
 	if pErr != nil {
 		t.Fatal(pErr)
 	}
@@ -850,7 +1115,7 @@ func TestRangeCacheHandleDoubleSplit(t *testing.T) {
 			// such that a RangeKeyMismatchError is returned.
 			_, evictToken := doLookup(ctx, db.cache, "az")
 			// mismatchErrRange mocks out a RangeKeyMismatchError.Range response.
-			ranges, _, pErr := db.getDescriptors(roachpb.RKey("aa"), false)
+			ranges, _, pErr := db.getDescriptors(roachpb.RKey("aa"), testLookupOptions.PrefetchCount, false)
 			if pErr != nil {
 				t.Fatal(pErr)
 			}
@@ -893,7 +1158,7 @@ func TestRangeCacheHandleDoubleSplit(t *testing.T) {
 					ctx, getRecAndFinish := tracing.ContextWithRecordingSpan(ctx, db.cache.tracer, "test")
 					defer getRecAndFinish()
 					tok, err := db.cache.lookupInternal(
-						ctx, key, oldToken, reverseScan)
+						ctx, key, oldToken, reverseScan, testLookupOptions)
 					require.NoError(t, err)
 					desc = tok.Desc()
 					if reverseScan {
@@ -1213,6 +1478,362 @@ func TestRangeCacheClearOlderOverlapping(t *testing.T) {
 	}
 }
 
+// TestRangeCacheInsertLookupResultsKeepsUnchanged verifies that re-resolving a
+// span only replaces the cached descriptors that actually changed, leaving the
+// others (including their lease information and their LRU position) untouched.
+func TestRangeCacheInsertLookupResultsKeepsUnchanged(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	rep1 := roachpb.ReplicaDescriptor{
+		NodeID:    1,
+		StoreID:   1,
+		ReplicaID: 1,
+	}
+	mkDesc := func(start, end string, gen roachpb.RangeGeneration) roachpb.RangeDescriptor {
+		return roachpb.RangeDescriptor{
+			StartKey:         roachpb.RKey(start),
+			EndKey:           roachpb.RKey(end),
+			InternalReplicas: []roachpb.ReplicaDescriptor{rep1},
+			Generation:       gen,
+		}
+	}
+	descAB2 := mkDesc("a", "b", 2)
+	descBC2 := mkDesc("b", "c", 2)
+	descBC3 := mkDesc("b", "c", 3)
+	descCD2 := mkDesc("c", "d", 2)
+	descDE2 := mkDesc("d", "e", 2)
+
+	st := cluster.MakeTestingClusterSettings()
+	tr := tracing.NewTracer()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	cache := NewRangeCache(st, nil /* db */, staticSize(3), stopper, tr)
+
+	// Populate the cache in an order that differs from the key order, so that
+	// re-adding the unchanged entries in key order would be observable in the
+	// eviction order.
+	cache.Insert(ctx,
+		roachpb.RangeInfo{Desc: descCD2},
+		roachpb.RangeInfo{Desc: descAB2, Lease: roachpb.Lease{Replica: rep1, Sequence: 1}},
+		roachpb.RangeInfo{Desc: descBC2},
+	)
+
+	// Re-resolve [a,d), with only the middle descriptor having changed.
+	func() {
+		cache.rangeCache.Lock()
+		defer cache.rangeCache.Unlock()
+		inserted := cache.insertLookupResultsLocked(ctx, []*CacheEntry{
+			{desc: descAB2}, {desc: descBC3}, {desc: descCD2},
+		})
+		require.Len(t, inserted, 3)
+		for _, e := range inserted {
+			require.NotNil(t, e)
+		}
+		require.Equal(t, descBC3, *inserted[1].Desc())
+	}()
+
+	// The unchanged [a,b) entry kept its lease.
+	entAB := cache.GetCached(ctx, roachpb.RKey("a"), false /* inverted */)
+	require.NotNil(t, entAB)
+	require.NotNil(t, entAB.Lease())
+	require.Equal(t, roachpb.LeaseSequence(1), entAB.Lease().Sequence)
+	entBC := cache.GetCached(ctx, roachpb.RKey("b"), false /* inverted */)
+	require.NotNil(t, entBC)
+	require.Equal(t, descBC3, *entBC.Desc())
+
+	// Access-order probe: the cache is full, so adding another entry evicts the
+	// least recently added one. That must still be [c,d), which was inserted
+	// first and left untouched by the re-resolution.
+	cache.Insert(ctx, roachpb.RangeInfo{Desc: descDE2})
+	require.Nil(t, cache.GetCached(ctx, roachpb.RKey("c"), false /* inverted */))
+	for _, k := range []string{"a", "b", "d"} {
+		require.NotNil(t, cache.GetCached(ctx, roachpb.RKey(k), false /* inverted */), k)
+	}
+}
+
+// TestRangeCacheUpdateDescriptor verifies that out-of-band descriptor updates
+// replace older cached descriptors, leave unchanged ones (and their leases)
+// alone, and are ignored when the cache already has something newer.
+func TestRangeCacheUpdateDescriptor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	rep1 := roachpb.ReplicaDescriptor{
+		NodeID:    1,
+		StoreID:   1,
+		ReplicaID: 1,
+	}
+	mkDesc := func(gen roachpb.RangeGeneration) roachpb.RangeDescriptor {
+		return roachpb.RangeDescriptor{
+			StartKey:         roachpb.RKey("a"),
+			EndKey:           roachpb.RKey("c"),
+			InternalReplicas: []roachpb.ReplicaDescriptor{rep1},
+			Generation:       gen,
+		}
+	}
+	desc1, desc2, desc3 := mkDesc(1), mkDesc(2), mkDesc(3)
+
+	st := cluster.MakeTestingClusterSettings()
+	tr := tracing.NewTracer()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	cache := NewRangeCache(st, nil /* db */, staticSize(2<<10), stopper, tr)
+	cache.Insert(ctx, roachpb.RangeInfo{
+		Desc:  desc2,
+		Lease: roachpb.Lease{Replica: rep1, Sequence: 1},
+	})
+
+	// An identical descriptor keeps the cached lease.
+	cache.UpdateDescriptor(ctx, &desc2)
+	ent := cache.GetCached(ctx, roachpb.RKey("a"), false /* inverted */)
+	require.NotNil(t, ent)
+	require.Equal(t, desc2, *ent.Desc())
+	require.NotNil(t, ent.Lease())
+
+	// An older descriptor is ignored.
+	cache.UpdateDescriptor(ctx, &desc1)
+	ent = cache.GetCached(ctx, roachpb.RKey("a"), false /* inverted */)
+	require.NotNil(t, ent)
+	require.Equal(t, desc2, *ent.Desc())
+
+	// A newer descriptor replaces the cached one.
+	cache.UpdateDescriptor(ctx, &desc3)
+	ent = cache.GetCached(ctx, roachpb.RKey("a"), false /* inverted */)
+	require.NotNil(t, ent)
+	require.Equal(t, desc3, *ent.Desc())
+	require.Nil(t, ent.Lease())
+}
+
+// TestRangeCacheMaxBytes verifies that the cache evicts entries when their
+// estimated size exceeds the configured limit, even though it's well below the
+// limit on the number of entries.
+func TestRangeCacheMaxBytes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	mkDesc := func(start, end string, numReplicas int) roachpb.RangeDescriptor {
+		desc := roachpb.RangeDescriptor{
+			StartKey:   roachpb.RKey(start),
+			EndKey:     roachpb.RKey(end),
+			Generation: 1,
+		}
+		for i := 1; i <= numReplicas; i++ {
+			desc.InternalReplicas = append(desc.InternalReplicas, roachpb.ReplicaDescriptor{
+				NodeID:    roachpb.NodeID(i),
+				StoreID:   roachpb.StoreID(i),
+				ReplicaID: roachpb.ReplicaID(i),
+			})
+		}
+		return desc
+	}
+	descAB, descBC, descCD := mkDesc("a", "b", 1), mkDesc("b", "c", 1), mkDesc("c", "d", 2)
+	size := func(desc roachpb.RangeDescriptor) int64 {
+		return entrySize(rangeCacheKey(desc.StartKey), &CacheEntry{desc: desc})
+	}
+
+	st := cluster.MakeTestingClusterSettings()
+	tr := tracing.NewTracer()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	cache := NewRangeCache(st, nil /* db */, staticSize(2<<10), stopper, tr)
+	maxBytes := size(descAB) + size(descBC)
+	cache.SetMaxBytes(func() int64 { return maxBytes })
+
+	cache.Insert(ctx, roachpb.RangeInfo{Desc: descAB}, roachpb.RangeInfo{Desc: descBC})
+	require.Equal(t, maxBytes, cache.rangeCache.bytes)
+	require.NotNil(t, cache.GetCached(ctx, roachpb.RKey("a"), false /* inverted */))
+	require.NotNil(t, cache.GetCached(ctx, roachpb.RKey("b"), false /* inverted */))
+
+	// The larger descriptor needs more than the space of one of the others.
+	require.Greater(t, size(descCD), size(descBC))
+	require.Less(t, size(descCD), maxBytes)
+	cache.Insert(ctx, roachpb.RangeInfo{Desc: descCD})
+	require.Nil(t, cache.GetCached(ctx, roachpb.RKey("a"), false /* inverted */))
+	require.Nil(t, cache.GetCached(ctx, roachpb.RKey("b"), false /* inverted */))
+	require.NotNil(t, cache.GetCached(ctx, roachpb.RKey("c"), false /* inverted */))
+	require.Equal(t, size(descCD), cache.rangeCache.bytes)
+
+	cache.Clear()
+	require.Zero(t, cache.rangeCache.bytes)
+}
+
+// TestRangeCacheEvictByRangeID verifies that entries can be evicted by range
+// ID, and that the index by range ID follows the entries being replaced.
+func TestRangeCacheEvictByRangeID(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	mkDesc := func(
+		rangeID roachpb.RangeID, start, end string, gen roachpb.RangeGeneration,
+	) roachpb.RangeDescriptor {
+		return roachpb.RangeDescriptor{
+			RangeID:    rangeID,
+			StartKey:   roachpb.RKey(start),
+			EndKey:     roachpb.RKey(end),
+			Generation: gen,
+		}
+	}
+
+	st := cluster.MakeTestingClusterSettings()
+	tr := tracing.NewTracer()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	cache := NewRangeCache(st, nil /* db */, staticSize(2<<10), stopper, tr)
+	cached := func(key string) *CacheEntry {
+		return cache.GetCached(ctx, roachpb.RKey(key), false /* inverted */)
+	}
+
+	cache.Insert(ctx,
+		roachpb.RangeInfo{Desc: mkDesc(1, "a", "b", 1)},
+		roachpb.RangeInfo{Desc: mkDesc(2, "b", "d", 1)},
+	)
+	require.False(t, cache.EvictByRangeID(ctx, 3))
+	require.True(t, cache.EvictByRangeID(ctx, 1))
+	require.Nil(t, cached("a"))
+	require.NotNil(t, cached("b"))
+	require.False(t, cache.EvictByRangeID(ctx, 1))
+
+	// r2 splits into r2 and r3. The new r2 replaces the old one.
+	cache.Insert(ctx,
+		roachpb.RangeInfo{Desc: mkDesc(2, "b", "c", 2)},
+		roachpb.RangeInfo{Desc: mkDesc(3, "c", "d", 2)},
+	)
+	require.True(t, cache.EvictByRangeID(ctx, 3))
+	require.Nil(t, cached("c"))
+	require.NotNil(t, cached("b"))
+	require.True(t, cache.EvictByRangeID(ctx, 2))
+	require.Nil(t, cached("b"))
+	require.Empty(t, cache.rangeCache.byRangeID)
+}
+
+// TestRangeCacheOnEvict verifies that eviction callbacks are invoked for
+// evicted descriptors, but not for entries replaced with the same descriptor.
+func TestRangeCacheOnEvict(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	rep1 := roachpb.ReplicaDescriptor{
+		NodeID:    1,
+		StoreID:   1,
+		ReplicaID: 1,
+	}
+	mkDesc := func(
+		rangeID roachpb.RangeID, start, end string, gen roachpb.RangeGeneration,
+	) roachpb.RangeDescriptor {
+		return roachpb.RangeDescriptor{
+			RangeID:          rangeID,
+			StartKey:         roachpb.RKey(start),
+			EndKey:           roachpb.RKey(end),
+			InternalReplicas: []roachpb.ReplicaDescriptor{rep1},
+			Generation:       gen,
+		}
+	}
+	descAB, descBD := mkDesc(1, "a", "b", 1), mkDesc(2, "b", "d", 1)
+	descBC, descCD := mkDesc(2, "b", "c", 2), mkDesc(3, "c", "d", 2)
+	descDE, descEF := mkDesc(4, "d", "e", 1), mkDesc(5, "e", "f", 1)
+
+	st := cluster.MakeTestingClusterSettings()
+	tr := tracing.NewTracer()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	cache := NewRangeCache(st, nil /* db */, staticSize(3), stopper, tr)
+	var evicted []roachpb.RangeDescriptor
+	cache.OnEvict(func(desc *roachpb.RangeDescriptor) {
+		evicted = append(evicted, *desc)
+	})
+
+	cache.Insert(ctx, roachpb.RangeInfo{Desc: descAB}, roachpb.RangeInfo{Desc: descBD})
+	require.Empty(t, evicted)
+
+	// A lease update doesn't evict the descriptor.
+	cache.Insert(ctx, roachpb.RangeInfo{
+		Desc:  descAB,
+		Lease: roachpb.Lease{Replica: rep1, Sequence: 1},
+	})
+	require.Empty(t, evicted)
+
+	// A split evicts the old descriptor.
+	cache.Insert(ctx, roachpb.RangeInfo{Desc: descBC}, roachpb.RangeInfo{Desc: descCD})
+	require.Equal(t, []roachpb.RangeDescriptor{descBD}, evicted)
+
+	// So does an explicit eviction, by key or by range ID.
+	evicted = nil
+	require.True(t, cache.EvictByKey(ctx, roachpb.RKey("c")))
+	require.Equal(t, []roachpb.RangeDescriptor{descCD}, evicted)
+	evicted = nil
+	require.True(t, cache.EvictByRangeID(ctx, descBC.RangeID))
+	require.Equal(t, []roachpb.RangeDescriptor{descBC}, evicted)
+
+	// And making room for other entries, which evicts the least recently used
+	// one.
+	evicted = nil
+	cache.Insert(ctx, roachpb.RangeInfo{Desc: descDE}, roachpb.RangeInfo{Desc: descEF})
+	require.Empty(t, evicted)
+	cache.Insert(ctx, roachpb.RangeInfo{Desc: descCD})
+	require.Equal(t, []roachpb.RangeDescriptor{descAB}, evicted)
+
+	// And clearing the cache.
+	evicted = nil
+	cache.Clear()
+	require.ElementsMatch(t, []roachpb.RangeDescriptor{descCD, descDE, descEF}, evicted)
+}
+
+// TestScanPrefetch verifies that a ScanPrefetch is only attached to a context
+// once and that concurrent recordings keep the largest window.
+func TestScanPrefetch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	require.Nil(t, ScanPrefetchFromContext(ctx))
+	ctx = WithScanPrefetch(ctx)
+	sp := ScanPrefetchFromContext(ctx)
+	require.NotNil(t, sp)
+	require.Equal(t, sp, ScanPrefetchFromContext(WithScanPrefetch(ctx)))
+	require.Zero(t, sp.Window())
+
+	var wg sync.WaitGroup
+	for i := int64(1); i <= 64; i++ {
+		wg.Add(1)
+		go func(window int64) {
+			defer wg.Done()
+			sp.Record(window)
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, int64(64), sp.Window())
+
+	// The window never shrinks.
+	sp.Record(8)
+	require.Equal(t, int64(64), sp.Window())
+}
+
+// TestRangeCacheHTML verifies the contents of the cache's debug page.
+func TestRangeCacheHTML(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	db := initTestDescriptorDB(t)
+	defer db.stop()
+
+	doLookup(ctx, db.cache, "d")
+	doLookup(ctx, db.cache, "d")
+	doLookup(ctx, db.cache, "d")
+	ent := db.cache.GetCached(ctx, roachpb.RKey("d"), false /* inverted */)
+	require.NotNil(t, ent)
+	require.Equal(t, int64(2), ent.numHits())
+
+	page := db.cache.HTML()
+	require.Contains(t, page, fmt.Sprintf("<td>%d</td></tr>", ent.numHits()))
+	require.Contains(t, page, html.EscapeString(ent.Desc().String()))
+}
+
 // TestRangeCacheClearOverlappingMeta prevents regression of a bug which caused
 // a panic when clearing overlapping descriptors for [KeyMin, Meta2Key). The
 // issue was that when attempting to clear out descriptors which were subsumed
@@ -1492,7 +2113,8 @@ func TestRangeCacheEvictAndReplace(t *testing.T) {
 
 	// Check that initially the cache has an empty lease and a default
 	// closed timestamp policy.
-	tok, err := cache.LookupWithEvictionToken(ctx, startKey, EvictionToken{}, false /* useReverseScan */)
+	tok, err := cache.LookupWithEvictionToken(
+		ctx, startKey, EvictionToken{}, false /* useReverseScan */, testLookupOptions)
 	require.NoError(t, err)
 	require.Equal(t, desc1, *tok.Desc())
 	require.Nil(t, tok.Leaseholder())
@@ -1501,7 +2123,8 @@ func TestRangeCacheEvictAndReplace(t *testing.T) {
 	// EvictAndReplace() with a new descriptor.
 	ri.Desc = desc2
 	tok.EvictAndReplace(ctx, ri)
-	tok, err = cache.LookupWithEvictionToken(ctx, startKey, tok, false /* useReverseScan */)
+	tok, err = cache.LookupWithEvictionToken(
+		ctx, startKey, tok, false /* useReverseScan */, testLookupOptions)
 	require.NoError(t, err)
 	require.Equal(t, desc2, *tok.Desc())
 	require.Nil(t, tok.Leaseholder())
@@ -1513,7 +2136,8 @@ func TestRangeCacheEvictAndReplace(t *testing.T) {
 		Sequence: 1,
 	}
 	tok.EvictAndReplace(ctx, ri)
-	tok, err = cache.LookupWithEvictionToken(ctx, startKey, tok, false /* useReverseScan */)
+	tok, err = cache.LookupWithEvictionToken(
+		ctx, startKey, tok, false /* useReverseScan */, testLookupOptions)
 	require.NoError(t, err)
 	require.Equal(t, desc2, *tok.Desc())
 	require.NotNil(t, tok.Leaseholder())
@@ -1524,7 +2148,8 @@ func TestRangeCacheEvictAndReplace(t *testing.T) {
 	// EvictAndReplace() with a new closed timestamp policy.
 	ri.ClosedTimestampPolicy = roachpb.LEAD_FOR_GLOBAL_READS
 	tok.EvictAndReplace(ctx, ri)
-	tok, err = cache.LookupWithEvictionToken(ctx, startKey, tok, false /* useReverseScan */)
+	tok, err = cache.LookupWithEvictionToken(
+		ctx, startKey, tok, false /* useReverseScan */, testLookupOptions)
 	require.NoError(t, err)
 	require.Equal(t, desc2, *tok.Desc())
 	require.NotNil(t, tok.Leaseholder())
@@ -1536,7 +2161,8 @@ func TestRangeCacheEvictAndReplace(t *testing.T) {
 	// remove lease, and retain closed timestamp policy.
 	tok.speculativeDesc = &desc3
 	tok.EvictAndReplace(ctx)
-	tok, err = cache.LookupWithEvictionToken(ctx, startKey, tok, false /* useReverseScan */)
+	tok, err = cache.LookupWithEvictionToken(
+		ctx, startKey, tok, false /* useReverseScan */, testLookupOptions)
 	require.NoError(t, err)
 	require.Equal(t, desc3, *tok.Desc())
 	require.Nil(t, tok.Leaseholder())
@@ -1610,7 +2236,7 @@ func TestRangeCacheUpdateLease(t *testing.T) {
 
 	// Check that initially the cache has an empty lease. Then, we'll UpdateLease().
 	tok, err := cache.LookupWithEvictionToken(
-		ctx, desc1.StartKey, EvictionToken{}, false /* useReverseScan */)
+		ctx, desc1.StartKey, EvictionToken{}, false /* useReverseScan */, testLookupOptions)
 	require.NoError(t, err)
 	require.Equal(t, desc1, *tok.Desc())
 	require.Nil(t, tok.Leaseholder())
@@ -1672,7 +2298,7 @@ func TestRangeCacheUpdateLease(t *testing.T) {
 		ClosedTimestampPolicy: roachpb.LEAD_FOR_GLOBAL_READS,
 	})
 	tok, err = cache.LookupWithEvictionToken(
-		ctx, desc1.StartKey, EvictionToken{}, false /* useReverseScan */)
+		ctx, desc1.StartKey, EvictionToken{}, false /* useReverseScan */, testLookupOptions)
 	require.NoError(t, err)
 
 	// Update the cache.
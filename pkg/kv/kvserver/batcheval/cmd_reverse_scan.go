@@ -39,6 +39,11 @@ func ReverseScan(
 	var scanRes storage.MVCCScanResult
 	var err error
 
+	filter, err := makeScanFilter(args.Filter)
+	if err != nil {
+		return result.Result{}, err
+	}
+
 	opts := storage.MVCCScanOptions{
 		Inconsistent:     h.ReadConsistency != roachpb.CONSISTENT,
 		SkipLocked:       h.WaitPolicy == lock.WaitPolicy_SkipLocked,
@@ -52,6 +57,7 @@ func ReverseScan(
 		Reverse:          true,
 		MemoryAccount:    cArgs.EvalCtx.GetResponseMemoryAccount(),
 		LockTable:        cArgs.Concurrency,
+		Filter:           filter,
 	}
 
 	switch args.ScanFormat {
@@ -13,11 +13,13 @@ package batcheval
 import (
 	"context"
 	"fmt"
+	"regexp"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/errors"
 )
 
 func init() {
@@ -39,6 +41,11 @@ func Scan(
 	var scanRes storage.MVCCScanResult
 	var err error
 
+	filter, err := makeScanFilter(args.Filter)
+	if err != nil {
+		return result.Result{}, err
+	}
+
 	opts := storage.MVCCScanOptions{
 		Inconsistent:     h.ReadConsistency != roachpb.CONSISTENT,
 		SkipLocked:       h.WaitPolicy == lock.WaitPolicy_SkipLocked,
@@ -53,6 +60,7 @@ func Scan(
 		Reverse:          false,
 		MemoryAccount:    cArgs.EvalCtx.GetResponseMemoryAccount(),
 		LockTable:        cArgs.Concurrency,
+		Filter:           filter,
 	}
 
 	switch args.ScanFormat {
@@ -103,3 +111,33 @@ func Scan(
 	res.Local.EncounteredIntents = scanRes.Intents
 	return res, nil
 }
+
+// makeScanFilter translates a ScanFilter into a storage-level filter function.
+// It returns a nil function if the filter is nil or has no predicates.
+func makeScanFilter(
+	f *roachpb.ScanFilter,
+) (func(key roachpb.Key, rawValue []byte) bool, error) {
+	if f == nil || (f.KeyRegexp == "" && f.ValueTag == roachpb.ValueType_UNKNOWN) {
+		return nil, nil
+	}
+	var keyRE *regexp.Regexp
+	if f.KeyRegexp != "" {
+		var err error
+		if keyRE, err = regexp.Compile(f.KeyRegexp); err != nil {
+			return nil, errors.Wrap(err, "invalid scan filter key regexp")
+		}
+	}
+	valueTag := f.ValueTag
+	return func(key roachpb.Key, rawValue []byte) bool {
+		if keyRE != nil && !keyRE.Match(key) {
+			return false
+		}
+		if valueTag != roachpb.ValueType_UNKNOWN {
+			v := roachpb.Value{RawBytes: rawValue}
+			if v.GetTag() != valueTag {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
@@ -422,6 +422,8 @@ func (ir *IntentResolver) runAsyncTask(
 	if ir.testingKnobs.DisableAsyncIntentResolution {
 		return errors.New("intents not processed as async resolution is disabled")
 	}
+	pending := ir.Metrics.IntentResolverAsyncPending
+	pending.Inc(1)
 	err := ir.stopper.RunAsyncTaskEx(
 		// If we've successfully launched a background task, dissociate
 		// this work from our caller's context and timeout.
@@ -431,9 +433,13 @@ func (ir *IntentResolver) runAsyncTask(
 			Sem:        ir.sem,
 			WaitForSem: false,
 		},
-		taskFn,
+		func(ctx context.Context) {
+			defer pending.Dec(1)
+			taskFn(ctx)
+		},
 	)
 	if err != nil {
+		pending.Dec(1)
 		if errors.Is(err, stop.ErrThrottled) {
 			ir.Metrics.IntentResolverAsyncThrottled.Inc(1)
 			if allowSyncProcessing {
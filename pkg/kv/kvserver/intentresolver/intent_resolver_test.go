@@ -274,6 +274,7 @@ func TestCleanupIntentsAsyncThrottled(t *testing.T) {
 		}
 	}
 	wg.Wait()
+	assert.Equal(t, int64(defaultTaskLimit), ir.Metrics.IntentResolverAsyncPending.Value())
 	testIntents := []roachpb.Intent{
 		roachpb.MakeIntent(&txn.TxnMeta, roachpb.Key("a")),
 	}
@@ -287,6 +288,8 @@ func TestCleanupIntentsAsyncThrottled(t *testing.T) {
 	err = ir.CleanupIntentsAsync(context.Background(), testIntents, true)
 	assert.Nil(t, err)
 	assert.Equal(t, sf.len(), 0)
+	// Throttled tasks are not counted as pending.
+	assert.Equal(t, int64(defaultTaskLimit), ir.Metrics.IntentResolverAsyncPending.Value())
 }
 
 // TestCleanupIntentsAsync verifies that CleanupIntentsAsync sends the expected
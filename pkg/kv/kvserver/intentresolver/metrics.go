@@ -20,6 +20,12 @@ var (
 		Measurement: "Intent Resolutions",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaIntentResolverAsyncPending = metric.Metadata{
+		Name:        "intentresolver.async.pending",
+		Help:        "Number of asynchronous intent resolution tasks currently running",
+		Measurement: "Intent Resolutions",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaFinalizedTxnCleanupFailed = metric.Metadata{
 		Name: "intentresolver.finalized_txns.failed",
 		Help: "Number of finalized transaction cleanup failures. Transaction " +
@@ -42,6 +48,10 @@ var (
 type Metrics struct {
 	IntentResolverAsyncThrottled *metric.Counter
 
+	// Gauge tracking the number of async intent resolution tasks in flight,
+	// i.e. the depth of the async worker pool.
+	IntentResolverAsyncPending *metric.Gauge
+
 	// Counter tracking intent + transaction record cleanup failures.
 	FinalizedTxnCleanupFailed *metric.Counter
 
@@ -55,6 +65,7 @@ func (*Metrics) MetricStruct() {}
 func makeMetrics() Metrics {
 	return Metrics{
 		IntentResolverAsyncThrottled: metric.NewCounter(metaIntentResolverAsyncThrottled),
+		IntentResolverAsyncPending:   metric.NewGauge(metaIntentResolverAsyncPending),
 		FinalizedTxnCleanupFailed:    metric.NewCounter(metaFinalizedTxnCleanupFailed),
 		IntentResolutionFailed:       metric.NewCounter(metaIntentCleanupFailed),
 	}
@@ -430,6 +430,7 @@ type baseQueue struct {
 		replicas       map[roachpb.RangeID]*replicaItem   // Map from RangeID to replicaItem
 		priorityQ      priorityQueue                      // The priority queue
 		purgatory      map[roachpb.RangeID]PurgatoryError // Map of replicas to processing errors
+		lastErrors     map[roachpb.RangeID]queueFailure   // Map of replicas to their last processing errors
 		stopped        bool
 		// Some tests in this package disable queues.
 		disabled bool
@@ -485,6 +486,7 @@ func newBaseQueue(name string, impl queueImpl, store *Store, cfg queueConfig) *b
 		},
 	}
 	bq.mu.replicas = map[roachpb.RangeID]*replicaItem{}
+	bq.mu.lastErrors = map[roachpb.RangeID]queueFailure{}
 
 	return &bq
 }
@@ -518,6 +520,60 @@ func (bq *baseQueue) PurgatoryLength() int {
 	return len(bq.mu.purgatory)
 }
 
+// maxQueueLastErrors bounds the number of replicas whose last processing error
+// a queue remembers.
+const maxQueueLastErrors = 128
+
+// queueFailure records the error a queue last failed to process a replica
+// with.
+type queueFailure struct {
+	err string
+	at  time.Time
+}
+
+// recordErrorLocked records the error the queue failed to process the replica
+// with, replacing the replica's previous error. If the queue already remembers
+// maxQueueLastErrors replicas' errors, the oldest one is forgotten.
+func (bq *baseQueue) recordErrorLocked(rangeID roachpb.RangeID, err error) {
+	if _, ok := bq.mu.lastErrors[rangeID]; !ok && len(bq.mu.lastErrors) >= maxQueueLastErrors {
+		var oldestID roachpb.RangeID
+		var oldest time.Time
+		for id, f := range bq.mu.lastErrors {
+			if oldestID == 0 || f.at.Before(oldest) {
+				oldestID, oldest = id, f.at
+			}
+		}
+		delete(bq.mu.lastErrors, oldestID)
+	}
+	bq.mu.lastErrors[rangeID] = queueFailure{err: err.Error(), at: timeutil.Now()}
+}
+
+// queueState returns the state of the replica with the given RangeID in the
+// queue. ok is false if the replica is neither a member of the queue nor one
+// whose last processing attempt failed.
+func (bq *baseQueue) queueState(rangeID roachpb.RangeID) (_ kvserverpb.QueueState, ok bool) {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	item, ok := bq.mu.replicas[rangeID]
+	failure, failed := bq.mu.lastErrors[rangeID]
+	if !ok && !failed {
+		return kvserverpb.QueueState{}, false
+	}
+	state := kvserverpb.QueueState{Queue: bq.name}
+	if ok {
+		state.Queued = item.index >= 0
+		state.Priority = item.priority
+		state.Processing = item.processing
+	}
+	if err, ok := bq.mu.purgatory[rangeID]; ok {
+		state.PurgatoryError = err.Error()
+	}
+	if failed {
+		state.LastError = failure.err
+	}
+	return state, true
+}
+
 // SetDisabled turns queue processing off or on as directed.
 func (bq *baseQueue) SetDisabled(disabled bool) {
 	bq.mu.Lock()
@@ -785,7 +841,8 @@ func (bq *baseQueue) MaybeAddCallback(rangeID roachpb.RangeID, cb processCallbac
 	return false
 }
 
-// MaybeRemove removes the specified replica from the queue if enqueued.
+// MaybeRemove removes the specified replica from the queue if enqueued, and
+// forgets its last processing error.
 func (bq *baseQueue) MaybeRemove(rangeID roachpb.RangeID) {
 	bq.mu.Lock()
 	defer bq.mu.Unlock()
@@ -794,6 +851,7 @@ func (bq *baseQueue) MaybeRemove(rangeID roachpb.RangeID) {
 		return
 	}
 
+	delete(bq.mu.lastErrors, rangeID)
 	if item, ok := bq.mu.replicas[rangeID]; ok {
 		ctx := bq.AnnotateCtx(context.TODO())
 		if log.V(3) {
@@ -1075,6 +1133,9 @@ func (bq *baseQueue) finishProcessingReplica(
 	item.callbacks = nil
 	bq.removeFromReplicaSetLocked(repl.GetRangeID())
 	item = nil // prevent accidental use below
+	if err == nil {
+		delete(bq.mu.lastErrors, repl.GetRangeID())
+	}
 	bq.mu.Unlock()
 
 	if !processing {
@@ -1095,6 +1156,9 @@ func (bq *baseQueue) finishProcessingReplica(
 		// TODO(tschottdorf): once we start asserting zero failures in tests
 		// (and production), move benign failures into a dedicated category.
 		bq.failures.Inc(1)
+		bq.mu.Lock()
+		bq.recordErrorLocked(repl.GetRangeID(), err)
+		bq.mu.Unlock()
 
 		// Determine whether a failure is a purgatory error. If it is, add
 		// the failing replica to purgatory. Note that even if the item was
@@ -0,0 +1,97 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kvserver
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+)
+
+// QueuesHTML is exposed at /debug/queues. It lists, for each of the node's
+// stores, the members of each replica queue along with their priority,
+// processing state and purgatory error, as well as the last error each queue
+// failed to process each replica with.
+func (ls *Stores) QueuesHTML() string {
+	sb := &strings.Builder{}
+	_ = ls.VisitStores(func(s *Store) error {
+		fmt.Fprintf(sb, "<h3>Store %d</h3>\n", s.StoreID())
+		if s.scanner == nil {
+			return nil
+		}
+		for _, q := range s.scanner.queues {
+			if bq, ok := q.(interface{ writeHTML(*strings.Builder) }); ok {
+				bq.writeHTML(sb)
+			}
+		}
+		return nil
+	})
+	return sb.String()
+}
+
+// writeHTML writes the queue's members and the replicas whose last processing
+// attempt failed to sb.
+func (bq *baseQueue) writeHTML(sb *strings.Builder) {
+	escape := func(s string) string {
+		return html.EscapeString(s)
+	}
+
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+
+	fmt.Fprintf(sb, "<h4>%s queue (%d queued, %d in purgatory, %d failed)</h4>\n",
+		escape(bq.name), bq.mu.priorityQ.Len(), len(bq.mu.purgatory), len(bq.mu.lastErrors))
+
+	rangeIDs := make([]roachpb.RangeID, 0, len(bq.mu.replicas)+len(bq.mu.lastErrors))
+	for rangeID := range bq.mu.replicas {
+		rangeIDs = append(rangeIDs, rangeID)
+	}
+	for rangeID := range bq.mu.lastErrors {
+		if _, ok := bq.mu.replicas[rangeID]; !ok {
+			rangeIDs = append(rangeIDs, rangeID)
+		}
+	}
+	sort.Slice(rangeIDs, func(i, j int) bool { return rangeIDs[i] < rangeIDs[j] })
+
+	sb.WriteString("<table><tr>" +
+		"<th>range</th><th>state</th><th>priority</th><th>purgatory error</th>" +
+		"<th>last error</th>" +
+		"</tr>\n")
+	for _, rangeID := range rangeIDs {
+		var state, priority, purgErr, lastErr string
+		if item, ok := bq.mu.replicas[rangeID]; ok {
+			switch {
+			case item.processing:
+				state = "processing"
+			case item.index >= 0:
+				state = "queued"
+			default:
+				state = "purgatory"
+			}
+			priority = fmt.Sprintf("%.2f", item.priority)
+		} else {
+			state = "failed"
+		}
+		if err, ok := bq.mu.purgatory[rangeID]; ok {
+			purgErr = err.Error()
+		}
+		if f, ok := bq.mu.lastErrors[rangeID]; ok {
+			lastErr = fmt.Sprintf("%s: %s", f.at.Truncate(time.Millisecond), f.err)
+		}
+		fmt.Fprintf(sb, "<tr><td>r%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			rangeID, state, priority, escape(purgErr), escape(lastErr))
+	}
+	sb.WriteString("</table>\n")
+}
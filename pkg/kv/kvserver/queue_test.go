@@ -15,6 +15,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -22,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/spanconfig"
@@ -383,6 +385,11 @@ func TestBaseQueueAdd(t *testing.T) {
 	if bq.Length() != 1 {
 		t.Fatalf("expected length 1; got %d", bq.Length())
 	}
+	state, ok := bq.queueState(r.RangeID)
+	require.True(t, ok)
+	require.Equal(t, kvserverpb.QueueState{Queue: "test", Queued: true, Priority: 1.0}, state)
+	_, ok = bq.queueState(r.RangeID + 1)
+	require.False(t, ok)
 }
 
 // TestBaseQueueNoop verifies that only successful processes
@@ -840,6 +847,18 @@ func TestBaseQueuePurgatory(t *testing.T) {
 		}
 		return nil
 	})
+	state, ok := bq.queueState(repls[0].RangeID)
+	require.True(t, ok)
+	require.Equal(t, "test", state.Queue)
+	require.False(t, state.Queued)
+	require.False(t, state.Processing)
+	require.Equal(t, "test purgatory error", state.PurgatoryError)
+	// Every replica's error is remembered, not just the last one's.
+	for _, r := range repls {
+		state, ok := bq.queueState(r.RangeID)
+		require.True(t, ok)
+		require.Equal(t, "test purgatory error", state.LastError)
+	}
 
 	// Now, signal that purgatoried replicas should retry.
 	testQueue.pChan <- timeutil.Now()
@@ -907,6 +926,45 @@ func TestBaseQueuePurgatory(t *testing.T) {
 	if l := bq.Length(); l != 0 {
 		t.Errorf("expected empty priorityQ; got %d", l)
 	}
+	// ...and their errors were forgotten once they were processed successfully.
+	for _, r := range repls {
+		_, ok := bq.queueState(r.RangeID)
+		require.False(t, ok)
+	}
+}
+
+// TestBaseQueueLastErrorsBounded verifies that a queue forgets the oldest
+// replica errors once it remembers maxQueueLastErrors of them.
+func TestBaseQueueLastErrorsBounded(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	bq := &baseQueue{}
+	bq.mu.lastErrors = map[roachpb.RangeID]queueFailure{}
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	for i := 1; i <= maxQueueLastErrors; i++ {
+		bq.recordErrorLocked(roachpb.RangeID(i), errors.Newf("error %d", i))
+	}
+	// Make the errors' ages unambiguous: r1's is the oldest.
+	now := timeutil.Now()
+	for rangeID, f := range bq.mu.lastErrors {
+		f.at = now.Add(-time.Duration(maxQueueLastErrors+1-int(rangeID)) * time.Second)
+		bq.mu.lastErrors[rangeID] = f
+	}
+
+	// Replacing a remembered replica's error doesn't forget any other, and makes
+	// it the most recent one.
+	bq.recordErrorLocked(1, errors.New("error 1 again"))
+	require.Len(t, bq.mu.lastErrors, maxQueueLastErrors)
+	require.Equal(t, "error 1 again", bq.mu.lastErrors[1].err)
+
+	// A new replica's error makes the queue forget the oldest one.
+	bq.recordErrorLocked(maxQueueLastErrors+1, errors.New("new error"))
+	require.Len(t, bq.mu.lastErrors, maxQueueLastErrors)
+	require.NotContains(t, bq.mu.lastErrors, roachpb.RangeID(2))
+	require.Contains(t, bq.mu.lastErrors, roachpb.RangeID(1))
+	require.Contains(t, bq.mu.lastErrors, roachpb.RangeID(maxQueueLastErrors+1))
 }
 
 type processTimeoutQueueImpl struct {
@@ -967,6 +1025,16 @@ func TestBaseQueueProcessTimeout(t *testing.T) {
 		}
 		return nil
 	})
+
+	// The failure is reported even though the replica has left the queue.
+	state, ok := bq.queueState(r.RangeID)
+	require.True(t, ok)
+	require.False(t, state.Queued)
+	require.Contains(t, state.LastError, context.DeadlineExceeded.Error())
+	var sb strings.Builder
+	bq.writeHTML(&sb)
+	require.Contains(t, sb.String(), fmt.Sprintf("<td>r%d</td><td>failed</td>", r.RangeID))
+	require.Contains(t, sb.String(), context.DeadlineExceeded.Error())
 }
 
 type mvccStatsReplicaInQueue struct {
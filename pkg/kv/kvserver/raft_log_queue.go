@@ -288,7 +288,7 @@ func newTruncateDecision(ctx context.Context, r *Replica) (truncateDecision, err
 	}
 
 	// For all our followers, overwrite the RecentActive field (which is always
-	// true since we don't use CheckQuorum) with our own activity check.
+	// true unless CheckQuorum is enabled) with our own activity check.
 	r.mu.RLock()
 	log.Eventf(ctx, "raft status before lastUpdateTimes check: %+v", raftStatus.Progress)
 	log.Eventf(ctx, "lastUpdateTimes: %+v", r.mu.lastUpdateTimes)
@@ -561,8 +561,8 @@ type Replica struct {
 		// every time a Raft message is received from a peer.
 		// Note that superficially it seems that similar information is contained in the
 		// Progress of a RaftStatus, which has a RecentActive field. However, that field
-		// is always true unless CheckQuorum is active, which at the time of writing in
-		// CockroachDB is not the case.
+		// is always true unless CheckQuorum is active, which in CockroachDB is not
+		// the case by default (see base.RaftConfig.RaftEnableCheckQuorum).
 		//
 		// The lastUpdateTimes map is also updated when a leaseholder steps up
 		// (making the assumption that all followers are live at that point),
@@ -1267,6 +1267,10 @@ func (r *Replica) State(ctx context.Context) kvserverpb.RangeInfo {
 	// it's best to keep it out of the Replica.mu critical section.
 	ri.RangefeedRegistrations = int64(r.numRangefeedRegistrations())
 
+	// NB: the queues have their own locks, so also query them before locking
+	// Replica.mu.
+	ri.Queues = r.store.queueStates(r.RangeID)
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	ri.ReplicaState = *(protoutil.Clone(&r.mu.state)).(*kvserverpb.ReplicaState)
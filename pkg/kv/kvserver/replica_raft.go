@@ -513,6 +513,13 @@ func (r *Replica) hasPendingProposalQuotaRLocked() bool {
 	return !r.mu.proposalQuota.Full()
 }
 
+// raftCheckQuorumEnabled is part of the quiescer interface. It returns whether
+// the replica's Raft group runs with CheckQuorum, in which case the range must
+// not quiesce. See base.RaftConfig.RaftEnableCheckQuorum.
+func (r *Replica) raftCheckQuorumEnabled() bool {
+	return r.store.cfg.RaftEnableCheckQuorum
+}
+
 var errRemoved = errors.New("replica removed")
 
 // stepRaftGroup calls Step on the replica's RawNode with the provided request's
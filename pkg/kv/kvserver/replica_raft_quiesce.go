@@ -200,6 +200,7 @@ type quiescer interface {
 	ownsValidLeaseRLocked(ctx context.Context, now hlc.ClockTimestamp) bool
 	mergeInProgressRLocked() bool
 	isDestroyedRLocked() (DestroyReason, error)
+	raftCheckQuorumEnabled() bool
 }
 
 // laggingReplicaSet is a set containing liveness information about replicas
@@ -276,6 +277,12 @@ func shouldReplicaQuiesce(
 	if testingDisableQuiescence {
 		return nil, nil, false
 	}
+	if q.raftCheckQuorumEnabled() {
+		if log.V(4) {
+			log.Infof(ctx, "not quiescing: check quorum enabled")
+		}
+		return nil, nil, false
+	}
 	if q.hasPendingProposalsRLocked() {
 		if log.V(4) {
 			log.Infof(ctx, "not quiescing: proposals pending")
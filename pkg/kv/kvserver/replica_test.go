@@ -9906,6 +9906,7 @@ type testQuiescer struct {
 	ownsValidLease  bool
 	mergeInProgress bool
 	isDestroyed     bool
+	checkQuorum     bool
 
 	// Not used to implement quiescer, but used by tests.
 	livenessMap liveness.IsLiveMap
@@ -9955,6 +9956,10 @@ func (q *testQuiescer) isDestroyedRLocked() (DestroyReason, error) {
 	return 0, nil
 }
 
+func (q *testQuiescer) raftCheckQuorumEnabled() bool {
+	return q.checkQuorum
+}
+
 func TestShouldReplicaQuiesce(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -10033,6 +10038,10 @@ func TestShouldReplicaQuiesce(t *testing.T) {
 		q.mergeInProgress = true
 		return q
 	})
+	test(false, func(q *testQuiescer) *testQuiescer {
+		q.checkQuorum = true
+		return q
+	})
 	test(false, func(q *testQuiescer) *testQuiescer {
 		q.isDestroyed = true
 		return q
@@ -284,7 +284,8 @@ func newRaftConfig(
 		Storage:                   strg,
 		Logger:                    logger,
 
-		PreVote: true,
+		PreVote:     true,
+		CheckQuorum: storeCfg.RaftEnableCheckQuorum,
 	}
 }
 
@@ -3422,6 +3423,27 @@ func (s *Store) AllocatorDryRun(ctx context.Context, repl *Replica) (tracingpb.R
 	return collectAndFinish(), nil
 }
 
+// queueStates returns the state of the replica with the given RangeID in each
+// of the store's queues that it is a member of.
+func (s *Store) queueStates(rangeID roachpb.RangeID) []kvserverpb.QueueState {
+	if s.scanner == nil {
+		return nil
+	}
+	var states []kvserverpb.QueueState
+	for _, q := range s.scanner.queues {
+		bq, ok := q.(interface {
+			queueState(roachpb.RangeID) (kvserverpb.QueueState, bool)
+		})
+		if !ok {
+			continue
+		}
+		if state, ok := bq.queueState(rangeID); ok {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
 // Enqueue runs the given replica through the requested queue. If `async` is
 // specified, the replica is enqueued into the requested queue for asynchronous
 // processing and this method returns nothing. Otherwise, it returns all trace
@@ -235,6 +235,11 @@ func (m *MockTransactionalSender) HasPerformedWrites() bool {
 	panic("unimplemented")
 }
 
+// Stats is part of the TxnSender interface.
+func (m *MockTransactionalSender) Stats() TxnStats {
+	return TxnStats{Restarts: int32(m.txn.Epoch)}
+}
+
 // MockTxnSenderFactory is a TxnSenderFactory producing MockTxnSenders.
 type MockTxnSenderFactory struct {
 	senderFunc func(context.Context, *roachpb.Transaction, roachpb.BatchRequest) (
@@ -12,6 +12,7 @@ package kv
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -331,6 +332,36 @@ type TxnSender interface {
 
 	// HasPerformedWrites returns true if a write has been performed.
 	HasPerformedWrites() bool
+
+	// Stats returns statistics about the transaction so far.
+	Stats() TxnStats
+}
+
+// TxnStats are statistics about a single transaction, as observed by its
+// TxnSender. They are meant to help applications find their contended
+// transactions; aggregate statistics across all transactions are exported
+// through the TxnCoordSender's metrics.
+type TxnStats struct {
+	// Restarts is the number of times the transaction was restarted at a new
+	// epoch. Restarts that required a new transaction (e.g. after the
+	// transaction was aborted) are not included.
+	Restarts int32
+	// Refreshes is the number of times the transaction successfully refreshed
+	// its reads to a higher timestamp, avoiding a restart.
+	Refreshes int64
+	// IntentsWritten is the number of intents written by the transaction. Keys
+	// written again in a later epoch are counted again.
+	IntentsWritten int64
+	// BytesWritten is the size of the keys and values written by the
+	// transaction.
+	BytesWritten int64
+	// LockSpans is the number of spans over which the transaction is tracking
+	// intents and locks. Once the lock footprint is condensed, a single span
+	// can cover many intents.
+	LockSpans int
+	// Duration is the wall time since the transaction sent its first request,
+	// up to the moment it was finalized.
+	Duration time.Duration
 }
 
 // SteppingMode is the argument type to ConfigureStepping.
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangecache"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/closedts"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -22,10 +23,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/admission/admissionpb"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
-	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
@@ -248,6 +249,13 @@ func (txn *Txn) Epoch() enginepb.TxnEpoch {
 	return txn.mu.sender.Epoch()
 }
 
+// Stats returns statistics about the transaction so far. See TxnStats.
+func (txn *Txn) Stats() TxnStats {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.Stats()
+}
+
 // statusLocked returns the txn proto status field.
 func (txn *Txn) statusLocked() roachpb.TransactionStatus {
 	return txn.mu.sender.TxnStatus()
@@ -535,6 +543,18 @@ func (txn *Txn) Inc(ctx context.Context, key interface{}, value int64) (KeyValue
 	return getOneRow(txn.Run(ctx, b), b)
 }
 
+// IncBounded is like Inc, but constrains the incremented value to the given
+// bounds. See Batch.IncBounded.
+//
+// key can be either a byte slice or a string.
+func (txn *Txn) IncBounded(
+	ctx context.Context, key interface{}, value int64, bounds roachpb.IncrementBounds,
+) (KeyValue, error) {
+	b := txn.NewBatch()
+	b.IncBounded(key, value, bounds)
+	return getOneRow(txn.Run(ctx, b), b)
+}
+
 func (txn *Txn) scan(
 	ctx context.Context, begin, end interface{}, maxRows int64, isReverse, forUpdate bool,
 ) ([]KeyValue, error) {
@@ -608,6 +628,9 @@ func (txn *Txn) ReverseScanForUpdate(
 func (txn *Txn) Iterate(
 	ctx context.Context, begin, end interface{}, pageSize int, f func([]KeyValue) error,
 ) error {
+	// Let the range lookups of each page pick up the prefetch window reached by
+	// the previous ones.
+	ctx = rangecache.WithScanPrefetch(ctx)
 	for {
 		rows, err := txn.Scan(ctx, begin, end, int64(pageSize))
 		if err != nil {
@@ -950,9 +973,18 @@ func (e *AutoCommitError) Error() string {
 // TransactionAbortedError, txn is reset to a fresh transaction, ready to be
 // used.
 func (txn *Txn) exec(ctx context.Context, fn func(context.Context, *Txn) error) (err error) {
+	// Optionally back off between attempts, as configured in the DBContext. The
+	// first attempt is not delayed.
+	retryOpts := txn.db.ctx.TxnRetryOptions
+	var r retry.Retry
+	if retryOpts != nil {
+		r = retry.StartWithCtx(ctx, *retryOpts)
+		r.Next()
+	}
+
 	// Run fn in a retry loop until we encounter a success or
 	// error condition this loop isn't capable of handling.
-	for {
+	for attempt := 1; ; attempt++ {
 		if err := ctx.Err(); err != nil {
 			return err
 		}
@@ -999,6 +1031,20 @@ func (txn *Txn) exec(ctx context.Context, fn func(context.Context, *Txn) error)
 			break
 		}
 
+		if retryOpts != nil && !r.Next() {
+			// Either the context was canceled while backing off, or the retry
+			// limit was reached.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			break
+		}
+		if onRetry := txn.db.ctx.OnTxnRetry; onRetry != nil {
+			if cbErr := onRetry(ctx, attempt, err); cbErr != nil {
+				return cbErr
+			}
+		}
+
 		txn.PrepareForRetry(ctx)
 	}
 
@@ -1200,15 +1246,79 @@ func (txn *Txn) NegotiateAndSend(
 
 	// The read spans ranges, so bounded-staleness orchestration will need to be
 	// performed in two distinct phases - negotiation and execution. First we'll
-	// use the BoundedStalenessNegotiator to determines the timestamp to perform
-	// the read at and fix the transaction's timestamp to this result. Then we'll
-	// issue the request through the transaction, which will use the negotiated
-	// read timestamp from the previous phase to execute the read.
-	//
-	// TODO(nvanbenschoten): implement this. #67554.
+	// determine the timestamp to perform the read at and fix the transaction's
+	// timestamp to this result. Then we'll issue the request through the
+	// transaction, which will use the negotiated read timestamp from the
+	// previous phase to execute the read.
+	ts, err := txn.negotiateBoundedStalenessTimestamp(ctx, ba)
+	if err != nil {
+		return nil, roachpb.NewError(err)
+	}
+	if err := txn.SetFixedTimestamp(ctx, ts); err != nil {
+		return nil, roachpb.NewError(err)
+	}
+	ba.BoundedStaleness = nil
+	return txn.Send(ctx, ba)
+}
+
+// negotiateBoundedStalenessTimestamp determines the timestamp at which a
+// bounded staleness read that spans ranges should be performed. It is the
+// client-side equivalent of the server-side negotiation fast-path: the resolved
+// timestamp over all of the batch's read spans is computed using
+// QueryResolvedTimestamp requests, which are routed according to the batch's
+// routing policy and whose results are combined across ranges by the
+// DistSender. The result is then constrained by the batch's timestamp bounds.
+func (txn *Txn) negotiateBoundedStalenessTimestamp(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (hlc.Timestamp, error) {
+	cfg := ba.BoundedStaleness
+	var queryResBa roachpb.BatchRequest
+	queryResBa.RoutingPolicy = ba.RoutingPolicy
+	for _, ru := range ba.Requests {
+		span := ru.GetInner().Header().Span()
+		if len(span.EndKey) == 0 {
+			// QueryResolvedTimestamp is a ranged operation.
+			span.EndKey = span.Key.Next()
+		}
+		queryResBa.Add(&roachpb.QueryResolvedTimestampRequest{
+			RequestHeader: roachpb.RequestHeaderFromSpan(span),
+		})
+	}
+
+	br, pErr := txn.DB().GetFactory().NonTransactionalSender().Send(ctx, queryResBa)
+	if pErr != nil {
+		return hlc.Timestamp{}, pErr.GoError()
+	}
 
-	return nil, roachpb.NewError(unimplemented.NewWithIssue(67554,
-		"cross-range bounded staleness reads not yet implemented"))
+	var resTS hlc.Timestamp
+	for _, ru := range br.Responses {
+		ts := ru.GetQueryResolvedTimestamp().ResolvedTS
+		if resTS.IsEmpty() {
+			resTS = ts
+		} else {
+			resTS.Backward(ts)
+		}
+	}
+	if resTS.Less(cfg.MinTimestampBound) {
+		// The resolved timestamp was below the request's minimum timestamp bound.
+		// If the minimum timestamp bound should be strictly obeyed, reject the
+		// batch. Otherwise, read at the minimum timestamp bound. On follower
+		// replicas, this may result in the request being redirected to the
+		// current leaseholder. On the leaseholder, this may result in the request
+		// blocking on conflicting transactions.
+		if cfg.MinTimestampBoundStrict {
+			return hlc.Timestamp{}, roachpb.NewMinTimestampBoundUnsatisfiableError(
+				cfg.MinTimestampBound, resTS,
+			)
+		}
+		resTS = cfg.MinTimestampBound
+	}
+	if !cfg.MaxTimestampBound.IsEmpty() && cfg.MaxTimestampBound.LessEq(resTS) {
+		// The resolved timestamp was above the request's maximum timestamp bound.
+		// Drop the read timestamp to the maximum timestamp bound.
+		resTS = cfg.MaxTimestampBound.Prev()
+	}
+	return resTS, nil
 }
 
 // checks preconditions on BatchRequest and Txn for NegotiateAndSend.
@@ -25,7 +25,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/kvclientutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
-	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -316,8 +315,8 @@ func TestRollbackAfterAmbiguousCommit(t *testing.T) {
 // test, unlike that one, exercises client-side transaction logic in kv.Txn and
 // routing logic in kvcoord.DistSender.
 //
-// The multiRange=true variant is currently unimplemented, as it is blocked on
-// #67554.
+// The multiRange=true variant exercises the client-side timestamp negotiation
+// that is performed when a bounded staleness read spans ranges.
 //
 // The test's strict param dictates whether strict bounded staleness reads are
 // used or not. If set to true, the test is configured to never expect blocking.
@@ -342,10 +341,6 @@ func TestTxnNegotiateAndSendDoesNotBlock(t *testing.T) {
 }
 
 func testTxnNegotiateAndSendDoesNotBlock(t *testing.T, multiRange, strict, routeNearest bool) {
-	if multiRange {
-		skip.IgnoreLint(t, "unimplemented, blocked on #67554.")
-	}
-
 	const testTime = 1 * time.Second
 	ctx := context.Background()
 
@@ -377,8 +372,13 @@ func testTxnNegotiateAndSendDoesNotBlock(t *testing.T, multiRange, strict, route
 	}
 	keySpan := roachpb.Span{Key: scratchKey, EndKey: scratchKey.PrefixEnd()}
 
-	// TODO(nvanbenschoten): if multiRange, split on each key in keySet.
-	// if multiRange { ... }
+	if multiRange {
+		// Split on each key in keySet. The new ranges inherit the replicas and
+		// the leaseholder of the scratch range.
+		for _, key := range keySet[1:] {
+			tc.SplitRangeOrFatal(t, key)
+		}
+	}
 
 	var g errgroup.Group
 	var done int32
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -340,6 +341,123 @@ func TestRunTransactionRetryOnErrors(t *testing.T) {
 	}
 }
 
+// TestRunTransactionRetryBackoff verifies that the retry options and retry
+// callback configured in the DBContext are honored by DB.Txn.
+func TestRunTransactionRetryBackoff(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+	errStop := errors.New("stop retrying")
+
+	testCases := []struct {
+		name        string
+		maxRetries  int
+		stopAt      int // attempt at which OnTxnRetry bails out; 0 to never
+		expPuts     int
+		expAttempts []int
+		expErr      string
+	}{
+		{
+			name:        "max retries",
+			maxRetries:  3,
+			expPuts:     4,
+			expAttempts: []int{1, 2, 3},
+			expErr:      "terminated retryable error",
+		},
+		{
+			name:        "callback error",
+			stopAt:      2,
+			expPuts:     2,
+			expAttempts: []int{1, 2},
+			expErr:      errStop.Error(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			stopper := stop.NewStopper()
+			defer stopper.Stop(ctx)
+
+			var puts int
+			var attempts []int
+			dbCtx := DefaultDBContext(stopper)
+			dbCtx.TxnRetryOptions = &retry.Options{
+				InitialBackoff: time.Microsecond,
+				MaxBackoff:     time.Millisecond,
+				MaxRetries:     tc.maxRetries,
+			}
+			dbCtx.OnTxnRetry = func(_ context.Context, attempt int, err error) error {
+				require.True(t, errors.HasType(err, (*roachpb.TransactionRetryWithProtoRefreshError)(nil)))
+				attempts = append(attempts, attempt)
+				if attempt == tc.stopAt {
+					return errStop
+				}
+				return nil
+			}
+			db := NewDBWithContext(log.MakeTestingAmbientCtxWithNewTracer(), newTestTxnFactory(
+				func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+					if _, ok := ba.GetArg(roachpb.Put); ok {
+						puts++
+						return nil, roachpb.NewError(roachpb.NewTransactionRetryWithProtoRefreshError(
+							"foo", ba.Txn.ID, *ba.Txn))
+					}
+					return ba.CreateReply(), nil
+				}), clock, dbCtx)
+			err := db.Txn(ctx, func(ctx context.Context, txn *Txn) error {
+				return txn.Put(ctx, "a", "b")
+			})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expErr)
+			require.Equal(t, tc.expPuts, puts)
+			require.Equal(t, tc.expAttempts, attempts)
+		})
+	}
+}
+
+// TestDBRequestInterceptor verifies that the interceptor configured in the
+// DBContext sees both transactional and non-transactional batches, and that it
+// can retry batches that failed with an ambiguous result.
+func TestDBRequestInterceptor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	clock := hlc.NewClockWithSystemTimeSource(time.Nanosecond /* maxOffset */)
+
+	var sends int
+	var intercepted []string
+	dbCtx := DefaultDBContext(stopper)
+	dbCtx.Interceptor = func(
+		ctx context.Context, ba roachpb.BatchRequest, next Sender,
+	) (*roachpb.BatchResponse, *roachpb.Error) {
+		intercepted = append(intercepted, ba.Summary())
+		br, pErr := next.Send(ctx, ba)
+		if pErr != nil && errors.HasType(pErr.GoError(), (*roachpb.AmbiguousResultError)(nil)) {
+			br, pErr = next.Send(ctx, ba)
+		}
+		return br, pErr
+	}
+	factory := MakeMockTxnSenderFactoryWithNonTxnSender(
+		newTestTxnFactory(nil).(MockTxnSenderFactory).senderFunc,
+		func(ctx context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+			sends++
+			if sends == 1 {
+				return nil, roachpb.NewError(roachpb.NewAmbiguousResultErrorf("boom"))
+			}
+			return ba.CreateReply(), nil
+		})
+	db := NewDBWithContext(log.MakeTestingAmbientCtxWithNewTracer(), factory, clock, dbCtx)
+
+	_, err := db.Inc(ctx, "a", 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, sends)
+	require.NoError(t, db.Txn(ctx, func(ctx context.Context, txn *Txn) error {
+		return txn.Put(ctx, "b", "c")
+	}))
+	require.Equal(t, []string{"1 Inc", "1 Put", "1 EndTxn"}, intercepted)
+}
+
 // TestTransactionStatus verifies that transactions always have their
 // status updated correctly.
 func TestTransactionStatus(t *testing.T) {
@@ -553,10 +671,34 @@ func TestTxnNegotiateAndSend(t *testing.T) {
 		ts10 := hlc.Timestamp{WallTime: 10}
 		ts20 := hlc.Timestamp{WallTime: 20}
 		clock := hlc.NewClock(timeutil.NewManualTime(timeutil.Unix(0, 1)), time.Nanosecond /* maxOffset */)
-		txnSender := MakeMockTxnSenderFactoryWithNonTxnSender(nil /* senderFunc */, func(
+		txnSender := MakeMockTxnSenderFactoryWithNonTxnSender(func(
+			_ context.Context, txn *roachpb.Transaction, ba roachpb.BatchRequest,
+		) (*roachpb.BatchResponse, *roachpb.Error) {
+			// The execution phase of a cross-range read, at the negotiated
+			// timestamp.
+			require.False(t, fastPath)
+			require.Nil(t, ba.BoundedStaleness)
+			require.Equal(t, ts20, txn.ReadTimestamp)
+			br := ba.CreateReply()
+			br.Timestamp = txn.ReadTimestamp
+			br.Txn = txn.Clone()
+			return br, nil
+		}, func(
 			_ context.Context, ba roachpb.BatchRequest,
 		) (*roachpb.BatchResponse, *roachpb.Error) {
-			require.NotNil(t, ba.BoundedStaleness)
+			if ba.BoundedStaleness == nil {
+				// The negotiation phase of a cross-range read.
+				require.False(t, fastPath)
+				require.Equal(t, roachpb.RoutingPolicy_NEAREST, ba.RoutingPolicy)
+				require.Len(t, ba.Requests, 1)
+				qrt := ba.Requests[0].GetQueryResolvedTimestamp()
+				require.NotNil(t, qrt)
+				require.Equal(t, roachpb.Key("a"), qrt.Key)
+				require.Equal(t, roachpb.Key("a").Next(), qrt.EndKey)
+				br := ba.CreateReply()
+				br.Responses[0].GetQueryResolvedTimestamp().ResolvedTS = ts20
+				return br, nil
+			}
 			require.Equal(t, ts10, ba.BoundedStaleness.MinTimestampBound)
 			require.False(t, ba.BoundedStaleness.MinTimestampBoundStrict)
 			require.Zero(t, ba.BoundedStaleness.MaxTimestampBound)
@@ -579,21 +721,111 @@ func TestTxnNegotiateAndSend(t *testing.T) {
 		ba.Add(roachpb.NewGet(roachpb.Key("a"), false))
 		br, pErr := txn.NegotiateAndSend(ctx, ba)
 
-		if fastPath {
-			require.Nil(t, pErr)
-			require.NotNil(t, br)
-			require.Equal(t, ts20, br.Timestamp)
-			require.True(t, txn.CommitTimestampFixed())
-			require.Equal(t, ts20, txn.CommitTimestamp())
-		} else {
-			require.Nil(t, br)
-			require.NotNil(t, pErr)
-			require.Regexp(t, "unimplemented: cross-range bounded staleness reads not yet implemented", pErr)
-			require.False(t, txn.CommitTimestampFixed())
-		}
+		require.Nil(t, pErr)
+		require.NotNil(t, br)
+		require.Equal(t, ts20, br.Timestamp)
+		require.True(t, txn.CommitTimestampFixed())
+		require.Equal(t, ts20, txn.CommitTimestamp())
 	})
 }
 
+// TestTxnNegotiateAndSendCrossRangeBounds tests that the timestamp negotiated
+// for a cross-range bounded staleness read respects the read's timestamp
+// bounds.
+func TestTxnNegotiateAndSendCrossRangeBounds(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	ts10 := hlc.Timestamp{WallTime: 10}
+	ts20 := hlc.Timestamp{WallTime: 20}
+	ts30 := hlc.Timestamp{WallTime: 30}
+	ts40 := hlc.Timestamp{WallTime: 40}
+
+	for _, test := range []struct {
+		name       string
+		resolvedTS []hlc.Timestamp
+		strict     bool
+		maxTSBound hlc.Timestamp
+
+		expTS  hlc.Timestamp
+		expErr string
+	}{
+		{
+			name:       "minimum resolved timestamp",
+			resolvedTS: []hlc.Timestamp{ts40, ts30},
+			expTS:      ts30,
+		},
+		{
+			name:       "resolved timestamp below min bound",
+			resolvedTS: []hlc.Timestamp{ts40, ts10},
+			expTS:      ts20,
+		},
+		{
+			name:       "resolved timestamp below min bound, strict",
+			resolvedTS: []hlc.Timestamp{ts40, ts10},
+			strict:     true,
+			expErr:     "bounded staleness read .* could not be satisfied",
+		},
+		{
+			name:       "resolved timestamp above max bound",
+			resolvedTS: []hlc.Timestamp{ts40, ts40},
+			maxTSBound: ts30,
+			expTS:      ts30.Prev(),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			clock := hlc.NewClock(timeutil.NewManualTime(timeutil.Unix(0, 1)), time.Nanosecond /* maxOffset */)
+			txnSender := MakeMockTxnSenderFactoryWithNonTxnSender(func(
+				_ context.Context, txn *roachpb.Transaction, ba roachpb.BatchRequest,
+			) (*roachpb.BatchResponse, *roachpb.Error) {
+				br := ba.CreateReply()
+				br.Timestamp = txn.ReadTimestamp
+				br.Txn = txn.Clone()
+				return br, nil
+			}, func(
+				_ context.Context, ba roachpb.BatchRequest,
+			) (*roachpb.BatchResponse, *roachpb.Error) {
+				if ba.BoundedStaleness != nil {
+					return nil, roachpb.NewError(&roachpb.OpRequiresTxnError{})
+				}
+				require.Len(t, ba.Requests, len(test.resolvedTS))
+				br := ba.CreateReply()
+				for i, ts := range test.resolvedTS {
+					br.Responses[i].GetQueryResolvedTimestamp().ResolvedTS = ts
+				}
+				return br, nil
+			})
+			db := NewDB(log.MakeTestingAmbientCtxWithNewTracer(), txnSender, clock, stopper)
+			txn := NewTxn(ctx, db, 0 /* gatewayNodeID */)
+
+			var ba roachpb.BatchRequest
+			ba.BoundedStaleness = &roachpb.BoundedStalenessHeader{
+				MinTimestampBound:       ts20,
+				MinTimestampBoundStrict: test.strict,
+				MaxTimestampBound:       test.maxTSBound,
+			}
+			ba.Add(roachpb.NewGet(roachpb.Key("a"), false))
+			ba.Add(roachpb.NewGet(roachpb.Key("b"), false))
+			br, pErr := txn.NegotiateAndSend(ctx, ba)
+
+			if test.expErr == "" {
+				require.Nil(t, pErr)
+				require.NotNil(t, br)
+				require.Equal(t, test.expTS, br.Timestamp)
+				require.True(t, txn.CommitTimestampFixed())
+				require.Equal(t, test.expTS, txn.CommitTimestamp())
+			} else {
+				require.Nil(t, br)
+				require.Regexp(t, test.expErr, pErr)
+				require.False(t, txn.CommitTimestampFixed())
+			}
+		})
+	}
+}
+
 // TestTxnNegotiateAndSendWithDeadline tests the behavior of NegotiateAndSend
 // when the transaction has a deadline.
 func TestTxnNegotiateAndSendWithDeadline(t *testing.T) {
@@ -722,18 +954,7 @@ func TestTxnNegotiateAndSendWithResumeSpan(t *testing.T) {
 		ts10 := hlc.Timestamp{WallTime: 10}
 		ts20 := hlc.Timestamp{WallTime: 20}
 		clock := hlc.NewClock(timeutil.NewManualTime(timeutil.Unix(0, 1)), time.Nanosecond /* maxOffset */)
-		txnSender := MakeMockTxnSenderFactoryWithNonTxnSender(nil /* senderFunc */, func(
-			_ context.Context, ba roachpb.BatchRequest,
-		) (*roachpb.BatchResponse, *roachpb.Error) {
-			require.NotNil(t, ba.BoundedStaleness)
-			require.Equal(t, ts10, ba.BoundedStaleness.MinTimestampBound)
-			require.False(t, ba.BoundedStaleness.MinTimestampBoundStrict)
-			require.Zero(t, ba.BoundedStaleness.MaxTimestampBound)
-			require.Equal(t, int64(2), ba.MaxSpanRequestKeys)
-
-			if !fastPath {
-				return nil, roachpb.NewError(&roachpb.OpRequiresTxnError{})
-			}
+		paginatedReply := func(ba roachpb.BatchRequest) *roachpb.BatchResponse {
 			br := ba.CreateReply()
 			br.Timestamp = ts20
 			scanResp := br.Responses[0].GetScan()
@@ -746,7 +967,46 @@ func TestTxnNegotiateAndSendWithResumeSpan(t *testing.T) {
 				EndKey: roachpb.Key("d"),
 			}
 			scanResp.ResumeReason = roachpb.RESUME_KEY_LIMIT
+			return br
+		}
+		txnSender := MakeMockTxnSenderFactoryWithNonTxnSender(func(
+			_ context.Context, txn *roachpb.Transaction, ba roachpb.BatchRequest,
+		) (*roachpb.BatchResponse, *roachpb.Error) {
+			// The execution phase of a cross-range read, at the negotiated
+			// timestamp.
+			require.False(t, fastPath)
+			require.Nil(t, ba.BoundedStaleness)
+			require.Equal(t, ts20, txn.ReadTimestamp)
+			require.Equal(t, int64(2), ba.MaxSpanRequestKeys)
+			br := paginatedReply(ba)
+			br.Txn = txn.Clone()
 			return br, nil
+		}, func(
+			_ context.Context, ba roachpb.BatchRequest,
+		) (*roachpb.BatchResponse, *roachpb.Error) {
+			if ba.BoundedStaleness == nil {
+				// The negotiation phase of a cross-range read is performed over the
+				// entire read span.
+				require.False(t, fastPath)
+				require.Zero(t, ba.MaxSpanRequestKeys)
+				require.Len(t, ba.Requests, 1)
+				qrt := ba.Requests[0].GetQueryResolvedTimestamp()
+				require.NotNil(t, qrt)
+				require.Equal(t, roachpb.Key("a"), qrt.Key)
+				require.Equal(t, roachpb.Key("d"), qrt.EndKey)
+				br := ba.CreateReply()
+				br.Responses[0].GetQueryResolvedTimestamp().ResolvedTS = ts20
+				return br, nil
+			}
+			require.Equal(t, ts10, ba.BoundedStaleness.MinTimestampBound)
+			require.False(t, ba.BoundedStaleness.MinTimestampBoundStrict)
+			require.Zero(t, ba.BoundedStaleness.MaxTimestampBound)
+			require.Equal(t, int64(2), ba.MaxSpanRequestKeys)
+
+			if !fastPath {
+				return nil, roachpb.NewError(&roachpb.OpRequiresTxnError{})
+			}
+			return paginatedReply(ba), nil
 		})
 		db := NewDB(log.MakeTestingAmbientCtxWithNewTracer(), txnSender, clock, stopper)
 		txn := NewTxn(ctx, db, 0 /* gatewayNodeID */)
@@ -760,26 +1020,19 @@ func TestTxnNegotiateAndSendWithResumeSpan(t *testing.T) {
 		ba.Add(roachpb.NewScan(roachpb.Key("a"), roachpb.Key("d"), false /* forUpdate */))
 		br, pErr := txn.NegotiateAndSend(ctx, ba)
 
-		if fastPath {
-			require.Nil(t, pErr)
-			require.NotNil(t, br)
-			// The negotiated timestamp should be returned and fixed.
-			require.Equal(t, ts20, br.Timestamp)
-			require.True(t, txn.CommitTimestampFixed())
-			require.Equal(t, ts20, txn.CommitTimestamp())
-			// Even though the response is paginated and carries a resume span.
-			require.Len(t, br.Responses, 1)
-			scanResp := br.Responses[0].GetScan()
-			require.Len(t, scanResp.Rows, 2)
-			require.NotNil(t, scanResp.ResumeSpan)
-			require.Equal(t, roachpb.Key("c"), scanResp.ResumeSpan.Key)
-			require.Equal(t, roachpb.Key("d"), scanResp.ResumeSpan.EndKey)
-			require.Equal(t, roachpb.RESUME_KEY_LIMIT, scanResp.ResumeReason)
-		} else {
-			require.Nil(t, br)
-			require.NotNil(t, pErr)
-			require.Regexp(t, "unimplemented: cross-range bounded staleness reads not yet implemented", pErr)
-			require.False(t, txn.CommitTimestampFixed())
-		}
+		require.Nil(t, pErr)
+		require.NotNil(t, br)
+		// The negotiated timestamp should be returned and fixed.
+		require.Equal(t, ts20, br.Timestamp)
+		require.True(t, txn.CommitTimestampFixed())
+		require.Equal(t, ts20, txn.CommitTimestamp())
+		// Even though the response is paginated and carries a resume span.
+		require.Len(t, br.Responses, 1)
+		scanResp := br.Responses[0].GetScan()
+		require.Len(t, scanResp.Rows, 2)
+		require.NotNil(t, scanResp.ResumeSpan)
+		require.Equal(t, roachpb.Key("c"), scanResp.ResumeSpan.Key)
+		require.Equal(t, roachpb.Key("d"), scanResp.ResumeSpan.EndKey)
+		require.Equal(t, roachpb.RESUME_KEY_LIMIT, scanResp.ResumeReason)
 	})
 }
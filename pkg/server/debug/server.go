@@ -233,6 +233,34 @@ func (ds *Server) RegisterClosedTimestampSideTransport(
 		})
 }
 
+type rangeCache interface {
+	HTML() string
+}
+
+// RegisterRangeCache registers a web endpoint dumping the contents of the
+// node's range descriptor cache.
+func (ds *Server) RegisterRangeCache(rc rangeCache) {
+	ds.mux.HandleFunc("/debug/range-cache",
+		func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Add("Content-type", "text/html")
+			fmt.Fprint(w, rc.HTML())
+		})
+}
+
+type storeQueues interface {
+	QueuesHTML() string
+}
+
+// RegisterStoreQueues registers a web endpoint listing the members of the
+// replica queues of the node's stores.
+func (ds *Server) RegisterStoreQueues(sq storeQueues) {
+	ds.mux.HandleFunc("/debug/queues",
+		func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Add("Content-type", "text/html")
+			fmt.Fprint(w, sq.QueuesHTML())
+		})
+}
+
 // ServeHTTP serves various tools under the /debug endpoint.
 func (ds *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	handler, _ := ds.mux.Handler(r)
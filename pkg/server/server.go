@@ -368,6 +368,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	}
 	distSender := kvcoord.NewDistSender(distSenderCfg)
 	registry.AddMetricStruct(distSender.Metrics())
+	registry.AddMetricStruct(distSender.RangeDescriptorCache().Metrics())
 
 	txnMetrics := kvcoord.MakeTxnMetrics(cfg.HistogramWindowInterval())
 	registry.AddMetricStruct(txnMetrics)
@@ -1576,6 +1577,8 @@ func (s *Server) PreStart(ctx context.Context) error {
 		return errors.Wrapf(err, "failed to register engines with debug server")
 	}
 	s.debug.RegisterClosedTimestampSideTransport(s.ctSender, s.node.storeCfg.ClosedTimestampReceiver)
+	s.debug.RegisterRangeCache(s.distSender.RangeDescriptorCache())
+	s.debug.RegisterStoreQueues(s.node.stores)
 
 	s.ctSender.Run(ctx, state.nodeID)
 
@@ -1894,6 +1894,29 @@ func MVCCIncrement(
 	txn *roachpb.Transaction,
 	inc int64,
 ) (int64, error) {
+	return MVCCBoundedIncrement(ctx, rw, ms, key, timestamp, localTimestamp, txn, inc, nil /* bounds */)
+}
+
+// MVCCBoundedIncrement is like MVCCIncrement, but constrains the incremented
+// value to the given bounds, if non-nil. If the incremented value would fall
+// outside of them, either the nearest bound is stored instead (if
+// bounds.Clamp is set) or a ConditionFailedError carrying the pre-existing
+// value is returned and the pre-existing integer is returned as the current
+// value. The value that was stored is returned.
+func MVCCBoundedIncrement(
+	ctx context.Context,
+	rw ReadWriter,
+	ms *enginepb.MVCCStats,
+	key roachpb.Key,
+	timestamp hlc.Timestamp,
+	localTimestamp hlc.ClockTimestamp,
+	txn *roachpb.Transaction,
+	inc int64,
+	bounds *roachpb.IncrementBounds,
+) (int64, error) {
+	if bounds != nil && bounds.Min > bounds.Max {
+		return 0, errors.Errorf("invalid increment bounds [%d, %d]", bounds.Min, bounds.Max)
+	}
 	iter := newMVCCIterator(rw, timestamp, false /* rangeKeyMasking */, IterOptions{
 		KeyTypes: IterKeyTypePointsAndRanges,
 		Prefix:   true,
@@ -1921,6 +1944,20 @@ func MVCCIncrement(
 			}
 		}
 		newInt64Val = int64Val + inc
+		if bounds != nil && (newInt64Val < bounds.Min || newInt64Val > bounds.Max) {
+			if !bounds.Clamp {
+				// Return the old value, since we've failed to modify it.
+				newInt64Val = int64Val
+				return roachpb.Value{}, &roachpb.ConditionFailedError{
+					ActualValue: value.ToPointer(),
+				}
+			}
+			if newInt64Val < bounds.Min {
+				newInt64Val = bounds.Min
+			} else {
+				newInt64Val = bounds.Max
+			}
+		}
 
 		newValue := roachpb.Value{}
 		newValue.SetInt(newInt64Val)
@@ -3147,6 +3184,7 @@ func mvccScanToBytes(
 		skipLocked:       opts.SkipLocked,
 		tombstones:       opts.Tombstones,
 		failOnMoreRecent: opts.FailOnMoreRecent,
+		filter:           opts.Filter,
 		keyBuf:           mvccScanner.keyBuf,
 	}
 
@@ -3290,6 +3328,18 @@ type MVCCScanOptions struct {
 	// LockTable is used to determine whether keys are locked in the in-memory
 	// lock table when scanning with the SkipLocked option.
 	LockTable LockTableView
+	// Filter, if set, is consulted for every kv pair the scan would otherwise
+	// emit (including tombstones, if Tombstones is set). Pairs for which it
+	// returns false are skipped. They are not returned, but they are charged
+	// against MaxKeys and TargetBytes like returned pairs: once the limits are
+	// used up, the scan stops with a ResumeSpan, which may be the case before
+	// any pair is returned. Without limits, the scan reads the entire span. The
+	// value is passed in its roachpb.Value RawBytes encoding and must not be
+	// retained by the filter.
+	//
+	// Filter cannot be combined with WholeRowsOfSize, since skipping keys
+	// would produce partial rows.
+	Filter func(key roachpb.Key, rawValue []byte) bool
 }
 
 func (opts *MVCCScanOptions) validate() error {
@@ -3302,6 +3352,9 @@ func (opts *MVCCScanOptions) validate() error {
 	if opts.Inconsistent && opts.FailOnMoreRecent {
 		return errors.Errorf("cannot allow inconsistent reads with fail on more recent option")
 	}
+	if opts.Filter != nil && opts.WholeRowsOfSize > 1 {
+		return errors.Errorf("cannot filter scans that return whole rows")
+	}
 	return nil
 }
 
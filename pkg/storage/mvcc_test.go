@@ -1137,6 +1137,81 @@ func TestMVCCScanMaxNum(t *testing.T) {
 	}
 }
 
+func TestMVCCScanWithFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			ts := hlc.Timestamp{WallTime: 1}
+			for i, key := range []roachpb.Key{testKey1, testKey2, testKey3, testKey4, testKey5} {
+				require.NoError(t, MVCCPut(ctx, engine, nil, key, ts, hlc.ClockTimestamp{},
+					roachpb.MakeValueFromString(fmt.Sprintf("v%d", i+1)), nil))
+			}
+
+			// Only return the even keys.
+			filter := func(key roachpb.Key, rawValue []byte) bool {
+				v := roachpb.Value{RawBytes: rawValue}
+				s, err := v.GetBytes()
+				require.NoError(t, err)
+				require.Equal(t, key[len(key)-1], s[len(s)-1])
+				return (key[len(key)-1]-'0')%2 == 0
+			}
+			for _, reverse := range []bool{false, true} {
+				res, err := MVCCScan(ctx, engine, testKey1, keyMax, ts,
+					MVCCScanOptions{Filter: filter, Reverse: reverse})
+				require.NoError(t, err)
+				var keys []roachpb.Key
+				for _, kv := range res.KVs {
+					keys = append(keys, kv.Key)
+				}
+				expected := []roachpb.Key{testKey2, testKey4}
+				if reverse {
+					expected = []roachpb.Key{testKey4, testKey2}
+				}
+				require.Equal(t, expected, keys)
+				require.Nil(t, res.ResumeSpan)
+			}
+
+			// Filtered keys are charged against MaxKeys.
+			res, err := MVCCScan(ctx, engine, testKey1, keyMax, ts,
+				MVCCScanOptions{Filter: filter, MaxKeys: 2})
+			require.NoError(t, err)
+			require.Len(t, res.KVs, 1)
+			require.Equal(t, testKey2, res.KVs[0].Key)
+			require.Equal(t, testKey3, res.ResumeSpan.Key)
+			require.Equal(t, roachpb.RESUME_KEY_LIMIT, res.ResumeReason)
+
+			// So the scan can stop before returning any key.
+			rejectAll := func(roachpb.Key, []byte) bool { return false }
+			res, err = MVCCScan(ctx, engine, testKey1, keyMax, ts,
+				MVCCScanOptions{Filter: rejectAll, MaxKeys: 2})
+			require.NoError(t, err)
+			require.Empty(t, res.KVs)
+			require.Equal(t, testKey3, res.ResumeSpan.Key)
+			require.Equal(t, roachpb.RESUME_KEY_LIMIT, res.ResumeReason)
+
+			// Filtered keys are also charged against TargetBytes, but at least one
+			// key is skipped so that the scan makes progress.
+			res, err = MVCCScan(ctx, engine, testKey1, keyMax, ts,
+				MVCCScanOptions{Filter: rejectAll, TargetBytes: 1})
+			require.NoError(t, err)
+			require.Empty(t, res.KVs)
+			require.Equal(t, testKey2, res.ResumeSpan.Key)
+			require.Equal(t, roachpb.RESUME_BYTE_LIMIT, res.ResumeReason)
+
+			// Filtering isn't compatible with whole rows.
+			_, err = MVCCScan(ctx, engine, testKey1, keyMax, ts,
+				MVCCScanOptions{Filter: filter, WholeRowsOfSize: 2})
+			require.Error(t, err)
+		})
+	}
+}
+
 func TestMVCCScanWithKeyPrefix(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -2886,6 +2961,66 @@ func TestMVCCResolveIntentTxnTimestampMismatch(t *testing.T) {
 	}
 }
 
+// TestMVCCBoundedIncrement verifies that MVCCBoundedIncrement either rejects
+// or clamps increments that would leave the configured bounds.
+func TestMVCCBoundedIncrement(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			bounds := &roachpb.IncrementBounds{Min: 0, Max: 10}
+			inc := func(wallTime int64, inc int64) (int64, error) {
+				return MVCCBoundedIncrement(ctx, engine, nil, testKey1,
+					hlc.Timestamp{WallTime: wallTime}, hlc.ClockTimestamp{}, nil, inc, bounds)
+			}
+
+			// Increments within the bounds are applied as usual.
+			newVal, err := inc(1, 8)
+			require.NoError(t, err)
+			require.Equal(t, int64(8), newVal)
+
+			// An increment past the upper bound is rejected and leaves the value
+			// untouched.
+			newVal, err = inc(2, 3)
+			var cErr *roachpb.ConditionFailedError
+			require.True(t, errors.As(err, &cErr), "unexpected error: %v", err)
+			actual, err := cErr.ActualValue.GetInt()
+			require.NoError(t, err)
+			require.Equal(t, int64(8), actual)
+			require.Equal(t, int64(8), newVal)
+
+			// The same goes for the lower bound.
+			_, err = inc(3, -9)
+			require.True(t, errors.HasType(err, (*roachpb.ConditionFailedError)(nil)), "unexpected error: %v", err)
+
+			// With clamping, the nearest bound is stored instead.
+			bounds.Clamp = true
+			newVal, err = inc(4, 3)
+			require.NoError(t, err)
+			require.Equal(t, int64(10), newVal)
+			newVal, err = inc(5, -20)
+			require.NoError(t, err)
+			require.Equal(t, int64(0), newVal)
+
+			value, _, err := MVCCGet(ctx, engine, testKey1, hlc.Timestamp{WallTime: 5}, MVCCGetOptions{})
+			require.NoError(t, err)
+			stored, err := value.GetInt()
+			require.NoError(t, err)
+			require.Equal(t, int64(0), stored)
+
+			// Inverted bounds are rejected.
+			bounds.Min, bounds.Max = 1, 0
+			_, err = inc(6, 1)
+			require.Error(t, err)
+		})
+	}
+}
+
 // TestMVCCConditionalPutOldTimestamp tests a case where a conditional
 // put with an older timestamp happens after a put with a newer timestamp.
 //
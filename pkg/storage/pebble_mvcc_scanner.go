@@ -373,6 +373,12 @@ type pebbleMVCCScanner struct {
 	isGet            bool
 	keyBuf           []byte
 	savedBuf         []byte
+	// filter is copied over from MVCCScanOptions.Filter. skippedKeys and
+	// skippedBytes account for the pairs it rejected, which are charged against
+	// maxKeys and targetBytes.
+	filter       func(key roachpb.Key, rawValue []byte) bool
+	skippedKeys  int64
+	skippedBytes int64
 	// cur* variables store the "current" record we're pointing to. Updated in
 	// updateCurrent. Note that the timestamp can be clobbered in the case of
 	// adding an intent from the intent history but is otherwise meaningful.
@@ -987,6 +993,28 @@ func (p *pebbleMVCCScanner) addAndAdvance(
 		}
 	}
 
+	// Skip keys rejected by the filter. They aren't returned, but they are
+	// charged against the limits so that a selective filter can't make the scan
+	// read an unbounded amount of data. If the limits have been used up, stop
+	// at this key; at least one key is always skipped, to guarantee progress.
+	if p.filter != nil && !p.filter(key, rawValue) {
+		size := int64(p.results.sizeOf(len(rawKey), len(rawValue)))
+		if p.skippedKeys > 0 {
+			if p.targetBytes > 0 && p.results.bytes+p.skippedBytes+size > p.targetBytes {
+				p.resumeReason = roachpb.RESUME_BYTE_LIMIT
+			} else if p.maxKeys > 0 && p.results.count+p.skippedKeys >= p.maxKeys {
+				p.resumeReason = roachpb.RESUME_KEY_LIMIT
+			}
+		}
+		if p.resumeReason != 0 {
+			p.resumeKey = key
+			return false
+		}
+		p.skippedKeys++
+		p.skippedBytes += size
+		return p.advanceKey()
+	}
+
 	// Check if adding the key would exceed a limit.
 	if p.targetBytes > 0 && p.results.bytes+int64(p.results.sizeOf(len(rawKey), len(rawValue))) > p.targetBytes {
 		p.resumeReason = roachpb.RESUME_BYTE_LIMIT
@@ -257,6 +257,7 @@ var charts = []sectionDescription{
 				Metrics: []string{
 					"distsender.batches",
 					"distsender.batches.partial",
+					"distsender.batches.partial.retried",
 					"distsender.batches.async.sent",
 					"distsender.batches.async.throttled",
 				},
@@ -271,12 +272,43 @@ var charts = []sectionDescription{
 				},
 				AxisLabel: "Error Count",
 			},
+			{
+				Title: "Hedged Reads",
+				Metrics: []string{
+					"distsender.rpc.hedged",
+					"distsender.rpc.hedged.wins",
+				},
+				AxisLabel: "RPCs",
+			},
 			{
 				Title: "Range Lookups",
 				Metrics: []string{
 					"distsender.rangelookups",
 				},
 			},
+			{
+				Title: "Range Cache",
+				Metrics: []string{
+					"rangecache.hits",
+					"rangecache.misses",
+				},
+				AxisLabel: "Lookups",
+			},
+			{
+				Title: "Range Cache Entries",
+				Metrics: []string{
+					"rangecache.evictions",
+					"rangecache.prefetch.adds",
+				},
+				AxisLabel: "Entries",
+			},
+			{
+				Title: "Range Cache Lookup Latency",
+				Metrics: []string{
+					"rangecache.lookup.latency",
+				},
+				AxisLabel: "Latency",
+			},
 			{
 				Title: "Rangefeed",
 				Metrics: []string{
@@ -1093,6 +1125,7 @@ var charts = []sectionDescription{
 				Title: "Intent Resolver",
 				Metrics: []string{
 					"intentresolver.async.throttled",
+					"intentresolver.async.pending",
 				},
 			},
 			{
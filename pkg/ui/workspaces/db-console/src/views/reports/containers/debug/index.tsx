@@ -367,6 +367,12 @@ export default function Debug() {
             url="debug/closedts-receiver"
           />
         </DebugTableRow>
+        <DebugTableRow title="Range Descriptor Cache">
+          <DebugTableLink name="Cache on this node" url="debug/range-cache" />
+        </DebugTableRow>
+        <DebugTableRow title="Replica Queues">
+          <DebugTableLink name="Queues on this node" url="debug/queues" />
+        </DebugTableRow>
       </DebugTable>
       <DebugTable
         heading={